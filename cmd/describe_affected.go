@@ -25,13 +25,17 @@ func init() {
 	describeAffectedCmd.DisableFlagParsing = false
 
 	describeAffectedCmd.PersistentFlags().String("repo-path", "", "Filesystem path to the already cloned target repository with which to compare the current branch: atmos describe affected --repo-path <path_to_already_cloned_repo>")
+	describeAffectedCmd.PersistentFlags().String("base", "", "Git commit SHA or reference in the local repository with which to compare the current branch, without cloning the target repository: atmos describe affected --base origin/main")
 	describeAffectedCmd.PersistentFlags().String("ref", "", "Git reference with which to compare the current branch: atmos describe affected --ref refs/heads/main. Refer to https://git-scm.com/book/en/v2/Git-Internals-Git-References for more details")
 	describeAffectedCmd.PersistentFlags().String("sha", "", "Git commit SHA with which to compare the current branch: atmos describe affected --sha 3a5eafeab90426bd82bf5899896b28cc0bab3073")
 	describeAffectedCmd.PersistentFlags().String("file", "", "Write the result to the file: atmos describe affected --ref refs/tags/v1.16.0 --file affected.json")
-	describeAffectedCmd.PersistentFlags().String("format", "json", "The output format: atmos describe affected --format=json|yaml ('json' is default)")
+	describeAffectedCmd.PersistentFlags().String("format", "json", "The output format: atmos describe affected --format=json|yaml|matrix ('json' is default). The 'matrix' format is the GitHub Actions matrix with the 'include' entries ordered by the dependency level")
 	describeAffectedCmd.PersistentFlags().Bool("verbose", false, "Print more detailed output when cloning and checking out the Git repository: atmos describe affected --verbose=true")
 	describeAffectedCmd.PersistentFlags().String("ssh-key", "", "Path to PEM-encoded private key to clone private repos using SSH: atmos describe affected --ssh-key <path_to_ssh_key>")
 	describeAffectedCmd.PersistentFlags().String("ssh-key-password", "", "Encryption password for the PEM-encoded private key if the key contains a password-encrypted PEM block: atmos describe affected --ssh-key <path_to_ssh_key> --ssh-key-password <password>")
+	describeAffectedCmd.PersistentFlags().Bool("include-dependants", false, "Include the components that depend on the affected components (directly or transitively): atmos describe affected --include-dependants=true")
+	describeAffectedCmd.PersistentFlags().Bool("include-diff", false, "Include the changed files and the changed keys in the stack config sections for each affected component: atmos describe affected --include-diff=true")
+	describeAffectedCmd.PersistentFlags().String("component-types", "", "Filter by specific component types: atmos describe affected --component-types=terraform|helmfile. Supported component types: terraform, helmfile")
 
 	describeCmd.AddCommand(describeAffectedCmd)
 }
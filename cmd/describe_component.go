@@ -26,6 +26,8 @@ func init() {
 	describeComponentCmd.PersistentFlags().StringP("format", "f", "yaml", "The output format: atmos describe component <component> -s <stack> --format=yaml|json ('yaml' is default)")
 	describeComponentCmd.PersistentFlags().String("file", "", "Write the result to the file: atmos describe component <component> -s <stack> --file component.yaml")
 
+	describeComponentCmd.PersistentFlags().Bool("provenance", false, "Show the stack config files, lines and scopes where the values in the 'vars', 'settings', 'env' and 'backend' sections are set: atmos describe component <component> -s <stack> --provenance")
+
 	err := describeComponentCmd.MarkPersistentFlagRequired("stack")
 	if err != nil {
 		u.PrintErrorToStdErrorAndExit(err)
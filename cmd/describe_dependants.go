@@ -28,6 +28,9 @@ func init() {
 	describeDependantsCmd.PersistentFlags().StringP("format", "f", "json", "The output format: atmos describe dependants <component> -s <stack> --format=json|yaml ('json' is default)")
 	describeDependantsCmd.PersistentFlags().String("file", "", "Write the result to the file: atmos describe dependants <component> -s <stack> --file dependants.yaml")
 
+	describeDependantsCmd.PersistentFlags().Bool("transitive", false, "Include the dependants of the dependants to any depth: atmos describe dependants <component> -s <stack> --transitive")
+	describeDependantsCmd.PersistentFlags().Int("max-depth", 0, "Maximum depth of the transitive dependants (enables '--transitive'): atmos describe dependants <component> -s <stack> --max-depth 2")
+
 	err := describeDependantsCmd.MarkPersistentFlagRequired("stack")
 	if err != nil {
 		u.PrintErrorToStdErrorAndExit(err)
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// describeGraphCmd renders the graph of the component dependencies, the component inheritance and the stack imports
+var describeGraphCmd = &cobra.Command{
+	Use:                "graph",
+	Short:              "Execute 'describe graph' command",
+	Long:               `This command renders the graph of the component dependencies, the component inheritance and the stack imports: atmos describe graph [options]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteDescribeGraphCmd(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	describeGraphCmd.DisableFlagParsing = false
+
+	describeGraphCmd.PersistentFlags().StringP("stack", "s", "", "Only show the components in the stack: atmos describe graph -s <stack>")
+	describeGraphCmd.PersistentFlags().String("components", "", "Only show the specified 'atmos' components: atmos describe graph --components=<component1>,<component2>")
+	describeGraphCmd.PersistentFlags().String("edges", "", "Only show the specified types of edges: atmos describe graph --edges=depends_on,inherits,imports (all types by default)")
+	describeGraphCmd.PersistentFlags().StringP("format", "f", "dot", "The output format: atmos describe graph --format=dot|mermaid|json|yaml ('dot' is default)")
+	describeGraphCmd.PersistentFlags().String("file", "", "Write the result to the file: atmos describe graph --format mermaid --file graph.mmd")
+
+	describeCmd.AddCommand(describeGraphCmd)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// terraformApplyAllCmd executes 'terraform apply' for multiple components in the order of their dependencies
+var terraformApplyAllCmd = &cobra.Command{
+	Use:                "apply-all",
+	Short:              "Execute 'terraform apply-all' command",
+	Long:               `This command executes 'terraform apply -auto-approve' for all the components selected by the filters, in the order of the dependencies between the components: atmos terraform apply-all [options] [-- <terraform flags>]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteTerraformApplyAllCmd(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	terraformApplyAllCmd.DisableFlagParsing = false
+
+	terraformApplyAllCmd.PersistentFlags().String("stacks", "",
+		"Only process the components in the specified stacks (comma-separated values).\n"+
+			"atmos terraform apply-all --stacks tenant1-ue2-staging,tenant1-ue2-prod",
+	)
+
+	terraformApplyAllCmd.PersistentFlags().String("components", "",
+		"Only process the specified 'atmos' components (comma-separated values).\n"+
+			"atmos terraform apply-all --components <component1>,<component2>",
+	)
+
+	terraformApplyAllCmd.PersistentFlags().String("affected-file", "",
+		"Only process the components from the output of 'atmos describe affected' command (in JSON or YAML format).\n"+
+			"Use '-' to read the output from stdin.\n"+
+			"atmos terraform apply-all --affected-file affected.json\n"+
+			"atmos describe affected | atmos terraform apply-all --affected-file -",
+	)
+
+	terraformApplyAllCmd.PersistentFlags().Int("max-parallel", 1, "Maximum number of components to process at the same time: atmos terraform apply-all --max-parallel 4")
+	terraformApplyAllCmd.PersistentFlags().Bool("dry-run", false, "atmos terraform apply-all --dry-run")
+
+	terraformCmd.AddCommand(terraformApplyAllCmd)
+}
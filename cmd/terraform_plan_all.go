@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// terraformPlanAllCmd executes 'terraform plan' for multiple components in the order of their dependencies
+var terraformPlanAllCmd = &cobra.Command{
+	Use:                "plan-all",
+	Short:              "Execute 'terraform plan-all' command",
+	Long:               `This command executes 'terraform plan' for all the components selected by the filters, in the order of the dependencies between the components: atmos terraform plan-all [options] [-- <terraform flags>]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteTerraformPlanAllCmd(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	terraformPlanAllCmd.DisableFlagParsing = false
+
+	terraformPlanAllCmd.PersistentFlags().String("stacks", "",
+		"Only process the components in the specified stacks (comma-separated values).\n"+
+			"atmos terraform plan-all --stacks tenant1-ue2-staging,tenant1-ue2-prod",
+	)
+
+	terraformPlanAllCmd.PersistentFlags().String("components", "",
+		"Only process the specified 'atmos' components (comma-separated values).\n"+
+			"atmos terraform plan-all --components <component1>,<component2>",
+	)
+
+	terraformPlanAllCmd.PersistentFlags().String("affected-file", "",
+		"Only process the components from the output of 'atmos describe affected' command (in JSON or YAML format).\n"+
+			"Use '-' to read the output from stdin.\n"+
+			"atmos terraform plan-all --affected-file affected.json\n"+
+			"atmos describe affected | atmos terraform plan-all --affected-file -",
+	)
+
+	terraformPlanAllCmd.PersistentFlags().Int("max-parallel", 1, "Maximum number of components to process at the same time: atmos terraform plan-all --max-parallel 4")
+	terraformPlanAllCmd.PersistentFlags().Bool("dry-run", false, "atmos terraform plan-all --dry-run")
+
+	terraformCmd.AddCommand(terraformPlanAllCmd)
+}
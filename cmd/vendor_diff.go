@@ -1,19 +1,30 @@
 package cmd
 
 import (
+	"os"
+
 	e "github.com/cloudposse/atmos/internal/exec"
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
+// vendorDiffDriftExitCode is the exit code of the `vendor diff` command if the vendored files differ from the upstream sources.
+// The other errors exit with the exit code 1
+const vendorDiffDriftExitCode = 2
+
 // vendorDiffCmd executes 'vendor diff' CLI commands
 var vendorDiffCmd = &cobra.Command{
 	Use:                "diff",
 	Short:              "Execute 'vendor diff' commands",
-	Long:               `This command executes 'atmos vendor diff' CLI commands`,
+	Long:               `This command compares the vendored component files with the upstream sources and mixins defined in 'component.yaml': atmos vendor diff --component <component>`,
 	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
 	Run: func(cmd *cobra.Command, args []string) {
 		err := e.ExecuteVendorDiffCmd(cmd, args)
+		// Exit with a distinct exit code if the drift is detected, so it can be distinguished from the other errors in CI
+		if _, ok := err.(*e.VendorDriftError); ok {
+			u.PrintErrorToStdError(err)
+			os.Exit(vendorDiffDriftExitCode)
+		}
 		if err != nil {
 			u.PrintErrorToStdErrorAndExit(err)
 		}
@@ -24,8 +35,8 @@ func init() {
 	vendorDiffCmd.PersistentFlags().StringP("component", "c", "", "atmos vendor diff --component <component>")
 	vendorDiffCmd.PersistentFlags().StringP("stack", "s", "", "atmos vendor diff --stack <stack>")
 	vendorDiffCmd.PersistentFlags().StringP("type", "t", "terraform", "atmos vendor diff --component <component> --type (terraform|helmfile)")
+	vendorDiffCmd.PersistentFlags().String("tags", "", "Only process the sources from the 'vendor.yaml' file that have any of the comma-separated tags: atmos vendor diff --tags <tag1>,<tag2>")
 	vendorDiffCmd.PersistentFlags().Bool("dry-run", false, "atmos vendor diff --component <component> --dry-run")
 
-	// Since this command is not implemented yet, exclude it from `atmos help`
-	// vendorCmd.AddCommand(vendorDiffCmd)
+	vendorCmd.AddCommand(vendorDiffCmd)
 }
@@ -24,6 +24,7 @@ func init() {
 	vendorPullCmd.PersistentFlags().StringP("component", "c", "", "atmos vendor pull --component <component>")
 	vendorPullCmd.PersistentFlags().StringP("stack", "s", "", "atmos vendor pull --stack <stack>")
 	vendorPullCmd.PersistentFlags().StringP("type", "t", "terraform", "atmos vendor pull --component <component> --type=terraform|helmfile")
+	vendorPullCmd.PersistentFlags().String("tags", "", "Only process the sources from the 'vendor.yaml' file that have any of the comma-separated tags: atmos vendor pull --tags <tag1>,<tag2>")
 	vendorPullCmd.PersistentFlags().Bool("dry-run", false, "atmos vendor pull --component <component> --dry-run")
 
 	vendorCmd.AddCommand(vendorPullCmd)
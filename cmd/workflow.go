@@ -26,11 +26,8 @@ func init() {
 	workflowCmd.PersistentFlags().Bool("dry-run", false, "atmos workflow <name> -f <file> --dry-run")
 	workflowCmd.PersistentFlags().StringP("stack", "s", "", "atmos workflow <name> -f <file> -s <stack>")
 	workflowCmd.PersistentFlags().String("from-step", "", "atmos workflow <name> -f <file> --from-step <step-name>")
-
-	err := workflowCmd.MarkPersistentFlagRequired("file")
-	if err != nil {
-		u.PrintErrorToStdErrorAndExit(err)
-	}
+	workflowCmd.PersistentFlags().StringArray("input", nil, "atmos workflow <name> -f <file> --input <name>=<value> --input <name>=<value>")
+	workflowCmd.PersistentFlags().Bool("resume", false, "atmos workflow <name> -f <file> --resume")
 
 	RootCmd.AddCommand(workflowCmd)
 }
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// workflowDescribeCmd shows the resolved steps of a workflow
+var workflowDescribeCmd = &cobra.Command{
+	Use:                "describe",
+	Short:              "Execute 'workflow describe' command",
+	Long:               `This command shows the resolved steps of a workflow with the effective stacks: atmos workflow describe <name> [options]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteWorkflowDescribeCmd(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	workflowDescribeCmd.DisableFlagParsing = false
+	workflowDescribeCmd.Flags().String("format", "yaml", "Output format: atmos workflow describe <name> --format=yaml|json")
+
+	workflowCmd.AddCommand(workflowDescribeCmd)
+}
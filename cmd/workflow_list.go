@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// workflowListCmd lists the workflows defined in the workflow files
+var workflowListCmd = &cobra.Command{
+	Use:                "list",
+	Short:              "Execute 'workflow list' command",
+	Long:               `This command lists the workflows defined in the workflow files: atmos workflow list [options]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteWorkflowListCmd(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	workflowListCmd.DisableFlagParsing = false
+	workflowListCmd.Flags().String("format", "table", "Output format: atmos workflow list --format=table|yaml|json")
+
+	workflowCmd.AddCommand(workflowListCmd)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	e "github.com/cloudposse/atmos/internal/exec"
+	u "github.com/cloudposse/atmos/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// workflowStatusCmd lists the recent workflow runs
+var workflowStatusCmd = &cobra.Command{
+	Use:                "status",
+	Short:              "Execute 'workflow status' command",
+	Long:               `This command lists the recent workflow runs recorded in the workflow state file: atmos workflow status [name] [options]`,
+	FParseErrWhitelist: struct{ UnknownFlags bool }{UnknownFlags: false},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := e.ExecuteWorkflowStatusCmd(cmd, args)
+		if err != nil {
+			u.PrintErrorToStdErrorAndExit(err)
+		}
+	},
+}
+
+func init() {
+	workflowStatusCmd.DisableFlagParsing = false
+	workflowStatusCmd.Flags().Int("limit", 10, "Maximum number of the most recent runs to show: atmos workflow status --limit 20")
+
+	workflowCmd.AddCommand(workflowStatusCmd)
+}
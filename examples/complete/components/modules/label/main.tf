@@ -0,0 +1,16 @@
+# The module uses the local module `policy`
+module "policy" {
+  source = "../policy"
+
+  name = var.name
+}
+
+variable "name" {
+  type        = string
+  description = "Name"
+}
+
+output "id" {
+  value       = module.policy.id
+  description = "ID"
+}
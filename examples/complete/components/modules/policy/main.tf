@@ -0,0 +1,9 @@
+variable "name" {
+  type        = string
+  description = "Name"
+}
+
+output "id" {
+  value       = lower(var.name)
+  description = "ID"
+}
@@ -0,0 +1,13 @@
+# The component uses the local module `label` and a module from the Terraform registry
+module "service_label" {
+  source = "../../../modules/label"
+
+  name = var.name
+}
+
+module "this" {
+  source  = "cloudposse/label/null"
+  version = "0.25.0"
+
+  name = var.name
+}
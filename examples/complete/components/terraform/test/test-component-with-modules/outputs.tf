@@ -0,0 +1,4 @@
+output "service_id" {
+  value       = module.service_label.id
+  description = "Service ID"
+}
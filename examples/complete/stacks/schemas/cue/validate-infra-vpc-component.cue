@@ -0,0 +1,17 @@
+// https://cuelang.org/docs/tutorials/
+// https://cuelang.org/docs/integrations/go/#processing-cue-in-go
+
+// 'atmos' unifies the CUE schema with the component configuration (the output of `atmos describe component`).
+// If the unification fails, or any of the fields declared in the schema is missing from the component configuration,
+// 'atmos' considers the validation failed
+
+vars: {
+	region: string
+
+	cidr_block: string & =~"^([0-9]{1,3}\\.){3}[0-9]{1,3}(/([0-9]|[1-2][0-9]|3[0-2]))?$"
+
+	map_public_ip_on_launch: bool
+
+	// VPC name must be a valid string from 2 to 20 alphanumeric chars
+	name: string & =~"^[a-zA-Z0-9]{2,20}$"
+}
@@ -0,0 +1 @@
+variable "zone" {}
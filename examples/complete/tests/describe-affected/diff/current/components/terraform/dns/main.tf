@@ -0,0 +1,3 @@
+variable "zone" {}
+
+variable "ttl" {}
@@ -0,0 +1,4 @@
+# `vpc` uses the local module `foo`, which uses the local module `bar`, which has a template in a subfolder
+module "foo" {
+  source = "../../modules/foo"
+}
@@ -0,0 +1,367 @@
+package exec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// dependencyGraph is the graph of the dependencies between Atmos components in Atmos stacks.
+// The dependencies are defined in the `settings.depends_on` sections of the components
+type dependencyGraph struct {
+	nodes map[string]*cfg.DependencyGraphNode
+	// The IDs of all the nodes sorted by stack, component and component type
+	ids []string
+	// The items of the `depends_on` sections that don't point to any component in any stack
+	unresolved []unresolvedDependency
+}
+
+// unresolvedDependency is an item of the `depends_on` section of the component that does not point to any component in any stack
+type unresolvedDependency struct {
+	id string
+	// The context of the dependency (the missing fields are taken from the component)
+	dependency cfg.Context
+}
+
+// dependencyGraphNodeId returns the ID of the node for the component of the type (`terraform` or `helmfile`) in the stack.
+// The same component name can be used for a terraform and a helmfile component in the same stack, so the ID includes the component type
+func dependencyGraphNodeId(componentType string, component string, stack string) string {
+	return fmt.Sprintf("%s/%s/%s", componentType, stack, component)
+}
+
+// dependencyGraphNodeStackSlug returns the stack slug of the node
+func dependencyGraphNodeStackSlug(node *cfg.DependencyGraphNode) string {
+	return fmt.Sprintf("%s-%s", node.Stack, strings.Replace(node.Component, "/", "-", -1))
+}
+
+// dependencyGraphContextKey returns the key to find the components by the name and the context
+func dependencyGraphContextKey(component string, namespace string, tenant string, environment string, stage string) string {
+	return strings.Join([]string{component, namespace, tenant, environment, stage}, "|")
+}
+
+// buildDependencyGraph builds the dependency graph of all the components in all the stacks (the output of `ExecuteDescribeStacks`).
+// Abstract components are not added to the graph.
+// If the context (namespace, tenant, environment, stage) of an item in the `depends_on` section is not provided,
+// the context of the component is used (the dependency is from the same Atmos stack as the component)
+func buildDependencyGraph(cliConfig cfg.CliConfiguration, stacks map[string]any) (*dependencyGraph, error) {
+	graph := &dependencyGraph{nodes: map[string]*cfg.DependencyGraphNode{}}
+
+	dependsOnSections := map[string]cfg.DependsOn{}
+	nodesByContext := map[string][]string{}
+
+	for stackName, stackSection := range stacks {
+		stackSectionMap, ok := stackSection.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		stackComponentsSection, ok := stackSectionMap["components"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for componentType, componentTypeSection := range stackComponentsSection {
+			componentTypeSectionMap, ok := componentTypeSection.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			for componentName, componentSection := range componentTypeSectionMap {
+				componentSectionMap, ok := componentSection.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				// Skip abstract components
+				if metadataSection, ok := componentSectionMap["metadata"].(map[any]any); ok {
+					if metadataType, ok := metadataSection["type"].(string); ok && metadataType == "abstract" {
+						continue
+					}
+				}
+
+				var componentVars cfg.Context
+				if varsSection, ok := componentSectionMap["vars"].(map[any]any); ok {
+					if err := mapstructure.Decode(varsSection, &componentVars); err != nil {
+						return nil, err
+					}
+				}
+
+				id := dependencyGraphNodeId(componentType, componentName, stackName)
+
+				// The stack and component names can contain slashes, so different components can produce the same ID
+				if existing, ok := graph.nodes[id]; ok {
+					return nil, fmt.Errorf("the %s component '%s' in the stack '%s' and the %s component '%s' in the stack '%s' have the same ID '%s' in the dependency graph",
+						existing.ComponentType, existing.Component, existing.Stack, componentType, componentName, stackName, id)
+				}
+
+				graph.nodes[id] = &cfg.DependencyGraphNode{
+					Id:            id,
+					Component:     componentName,
+					ComponentType: componentType,
+					ComponentPath: BuildComponentPath(cliConfig, componentSectionMap, componentType),
+					Namespace:     componentVars.Namespace,
+					Tenant:        componentVars.Tenant,
+					Environment:   componentVars.Environment,
+					Stage:         componentVars.Stage,
+					Stack:         stackName,
+				}
+
+				key := dependencyGraphContextKey(componentName, componentVars.Namespace, componentVars.Tenant, componentVars.Environment, componentVars.Stage)
+				nodesByContext[key] = append(nodesByContext[key], id)
+
+				if settingsSection, ok := componentSectionMap["settings"].(map[any]any); ok {
+					var settings cfg.Settings
+					if err := mapstructure.Decode(settingsSection, &settings); err != nil {
+						return nil, err
+					}
+					if len(settings.DependsOn) > 0 {
+						dependsOnSections[id] = settings.DependsOn
+					}
+				}
+			}
+		}
+	}
+
+	for id := range graph.nodes {
+		graph.ids = append(graph.ids, id)
+	}
+
+	sort.Slice(graph.ids, func(i, j int) bool {
+		a, b := graph.nodes[graph.ids[i]], graph.nodes[graph.ids[j]]
+		if a.Stack != b.Stack {
+			return a.Stack < b.Stack
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.ComponentType < b.ComponentType
+	})
+
+	for _, id := range graph.ids {
+		dependsOn, ok := dependsOnSections[id]
+		if !ok {
+			continue
+		}
+
+		node := graph.nodes[id]
+
+		for _, dependency := range sortedDependsOn(dependsOn) {
+			if dependency.Component == "" {
+				continue
+			}
+
+			key := dependencyGraphContextKey(
+				dependency.Component,
+				valueOrDefault(dependency.Namespace, node.Namespace),
+				valueOrDefault(dependency.Tenant, node.Tenant),
+				valueOrDefault(dependency.Environment, node.Environment),
+				valueOrDefault(dependency.Stage, node.Stage),
+			)
+
+			if len(nodesByContext[key]) == 0 {
+				graph.unresolved = append(graph.unresolved, unresolvedDependency{
+					id: id,
+					dependency: cfg.Context{
+						Component:   dependency.Component,
+						Namespace:   valueOrDefault(dependency.Namespace, node.Namespace),
+						Tenant:      valueOrDefault(dependency.Tenant, node.Tenant),
+						Environment: valueOrDefault(dependency.Environment, node.Environment),
+						Stage:       valueOrDefault(dependency.Stage, node.Stage),
+					},
+				})
+				continue
+			}
+
+			for _, dependencyId := range nodesByContext[key] {
+				if dependencyId == id {
+					continue
+				}
+				if !u.SliceContainsString(node.DependsOn, dependencyId) {
+					node.DependsOn = append(node.DependsOn, dependencyId)
+				}
+				dependencyNode := graph.nodes[dependencyId]
+				if !u.SliceContainsString(dependencyNode.Dependants, id) {
+					dependencyNode.Dependants = append(dependencyNode.Dependants, id)
+				}
+			}
+		}
+	}
+
+	for _, node := range graph.nodes {
+		sort.Strings(node.DependsOn)
+		sort.Strings(node.Dependants)
+	}
+
+	return graph, nil
+}
+
+// sortedDependsOn returns the items of the `depends_on` section sorted by their keys
+func sortedDependsOn(dependsOn cfg.DependsOn) []cfg.Context {
+	keys := make([]string, 0, len(dependsOn))
+	items := map[string]cfg.Context{}
+
+	for k, v := range dependsOn {
+		key := fmt.Sprintf("%v", k)
+		keys = append(keys, key)
+		items[key] = v
+	}
+
+	sort.Strings(keys)
+
+	result := make([]cfg.Context, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, items[k])
+	}
+
+	return result
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// findDependencyGraphCycle returns the first dependency cycle found in the graph (starting from the provided nodes),
+// or nil if there are no cycles
+func findDependencyGraphCycle(graph *dependencyGraph, ids []string) []string {
+	const (
+		notVisited = iota
+		visiting
+		visited
+	)
+
+	states := map[string]int{}
+
+	var visit func(id string, path []string) []string
+	visit = func(id string, path []string) []string {
+		path = append(path, id)
+
+		switch states[id] {
+		case visiting:
+			return path
+		case visited:
+			return nil
+		}
+
+		states[id] = visiting
+		for _, dependencyId := range graph.nodes[id].DependsOn {
+			if cycle := visit(dependencyId, path); cycle != nil {
+				return cycle
+			}
+		}
+		states[id] = visited
+
+		return nil
+	}
+
+	for _, id := range ids {
+		if cycle := visit(id, nil); cycle != nil {
+			// Return only the nodes that form the cycle
+			last := cycle[len(cycle)-1]
+			for i, c := range cycle {
+				if c == last {
+					return cycle[i:]
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// selectedDependencies returns the nodes from the selected nodes that the node depends on directly,
+// or transitively through the nodes that are not selected
+func selectedDependencies(graph *dependencyGraph, id string, selected map[string]bool) []string {
+	var result []string
+	visited := map[string]bool{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		for _, dependencyId := range graph.nodes[id].DependsOn {
+			if visited[dependencyId] {
+				continue
+			}
+			visited[dependencyId] = true
+
+			if selected[dependencyId] {
+				result = append(result, dependencyId)
+			} else {
+				visit(dependencyId)
+			}
+		}
+	}
+
+	visit(id)
+	sort.Strings(result)
+	return result
+}
+
+// findDependencyGraphCycles returns all the groups of the nodes that depend on each other (directly or transitively).
+// The groups are the strongly connected components of the graph with more than one node (Tarjan's algorithm)
+func findDependencyGraphCycles(graph *dependencyGraph) [][]string {
+	index := 0
+	indexes := map[string]int{}
+	lowLinks := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var cycles [][]string
+
+	var strongConnect func(id string)
+	strongConnect = func(id string) {
+		indexes[id] = index
+		lowLinks[id] = index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, dependencyId := range graph.nodes[id].DependsOn {
+			if _, visited := indexes[dependencyId]; !visited {
+				strongConnect(dependencyId)
+				if lowLinks[dependencyId] < lowLinks[id] {
+					lowLinks[id] = lowLinks[dependencyId]
+				}
+			} else if onStack[dependencyId] && indexes[dependencyId] < lowLinks[id] {
+				lowLinks[id] = indexes[dependencyId]
+			}
+		}
+
+		if lowLinks[id] != indexes[id] {
+			return
+		}
+
+		var group []string
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			group = append(group, last)
+			if last == id {
+				break
+			}
+		}
+
+		if len(group) > 1 {
+			sort.Strings(group)
+			cycles = append(cycles, group)
+		}
+	}
+
+	for _, id := range graph.ids {
+		if _, visited := indexes[id]; !visited {
+			strongConnect(id)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+
+	return cycles
+}
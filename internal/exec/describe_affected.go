@@ -3,6 +3,7 @@ package exec
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -45,8 +46,8 @@ func ExecuteDescribeAffectedCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if format != "" && format != "yaml" && format != "json" {
-		return fmt.Errorf("invalid '--format' flag '%s'. Valid values are 'json' (default) and 'yaml'", format)
+	if format != "" && format != "yaml" && format != "json" && format != "matrix" {
+		return fmt.Errorf("invalid '--format' flag '%s'. Valid values are 'json' (default), 'yaml' and 'matrix'", format)
 	}
 
 	if format == "" {
@@ -73,23 +74,73 @@ func ExecuteDescribeAffectedCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	includeDependants, err := flags.GetBool("include-dependants")
+	if err != nil {
+		return err
+	}
+
+	includeDiff, err := flags.GetBool("include-diff")
+	if err != nil {
+		return err
+	}
+
+	base, err := flags.GetString("base")
+	if err != nil {
+		return err
+	}
+
+	componentTypesCsv, err := flags.GetString("component-types")
+	if err != nil {
+		return err
+	}
+
+	var componentTypes []string
+	if componentTypesCsv != "" {
+		componentTypes = strings.Split(componentTypesCsv, ",")
+	}
+
 	if repoPath != "" && (ref != "" || sha != "" || sshKeyPath != "" || sshKeyPassword != "") {
 		return errors.New("if the '--repo-path' flag is specified, the '--ref', '--sha', '--ssh-key' and '--ssh-key-password' flags can't be used")
 	}
 
+	if base != "" && (repoPath != "" || ref != "" || sha != "" || sshKeyPath != "" || sshKeyPassword != "") {
+		return errors.New("if the '--base' flag is specified, the '--repo-path', '--ref', '--sha', '--ssh-key' and '--ssh-key-password' flags can't be used")
+	}
+
 	var affected []cfg.Affected
-	if repoPath == "" {
-		affected, err = ExecuteDescribeAffectedWithTargetRepoClone(cliConfig, ref, sha, sshKeyPath, sshKeyPassword, verbose)
+	var currentStacks map[string]any
+	if base != "" {
+		affected, currentStacks, err = ExecuteDescribeAffectedWithBase(cliConfig, base, verbose, includeDependants, includeDiff)
+	} else if repoPath == "" {
+		affected, currentStacks, err = ExecuteDescribeAffectedWithTargetRepoClone(cliConfig, ref, sha, sshKeyPath, sshKeyPassword, verbose, includeDependants, includeDiff)
 	} else {
-		affected, err = ExecuteDescribeAffectedWithTargetRepoPath(cliConfig, repoPath, verbose)
+		affected, currentStacks, err = ExecuteDescribeAffectedWithTargetRepoPath(cliConfig, repoPath, verbose, includeDependants, includeDiff)
 	}
 
 	if err != nil {
 		return err
 	}
 
+	if len(componentTypes) > 0 {
+		filtered := []cfg.Affected{}
+		for _, a := range affected {
+			if u.SliceContainsString(componentTypes, a.ComponentType) {
+				filtered = append(filtered, a)
+			}
+		}
+		affected = filtered
+	}
+
 	u.PrintInfoVerbose(verbose && file == "", fmt.Sprintf("\nAffected components and stacks: \n"))
 
+	if format == "matrix" {
+		matrix, err := BuildAffectedMatrix(cliConfig, currentStacks, affected)
+		if err != nil {
+			return err
+		}
+		return printOrWriteAffectedMatrix(file, matrix)
+	}
+
 	err = printOrWriteToFile(format, file, affected)
 	if err != nil {
 		return err
@@ -0,0 +1,126 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// BuildAffectedMatrix converts the list of the affected components to the GitHub Actions matrix (`include` entries).
+// The entries are ordered by the dependency level (defined in the `settings.depends_on` sections of the components),
+// so that the components at the same level can be processed in parallel after all the components at the previous levels.
+// The dependencies are read from the stacks (the output of `ExecuteDescribeStacks`) used to find the affected components.
+// The affected components that are not in the stacks are at the level 0.
+// A component is included once per stack. If it's in the list more than once, the entry with the lowest level is kept
+func BuildAffectedMatrix(cliConfig cfg.CliConfiguration, stacks map[string]any, affected []cfg.Affected) (cfg.AffectedMatrix, error) {
+	matrix := cfg.AffectedMatrix{Include: []cfg.AffectedMatrixEntry{}}
+
+	if len(affected) == 0 {
+		return matrix, nil
+	}
+
+	graph, err := buildDependencyGraph(cliConfig, stacks)
+	if err != nil {
+		return matrix, err
+	}
+
+	var ids []string
+	selected := map[string]bool{}
+
+	for _, a := range affected {
+		id := dependencyGraphNodeId(a.ComponentType, a.Component, a.Stack)
+		if _, ok := graph.nodes[id]; ok && !selected[id] {
+			ids = append(ids, id)
+			selected[id] = true
+		}
+	}
+
+	if cycle := findDependencyGraphCycle(graph, ids); cycle != nil {
+		return matrix, fmt.Errorf("the affected components have a dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	levels := map[string]int{}
+
+	var getLevel func(id string) int
+	getLevel = func(id string) int {
+		if level, ok := levels[id]; ok {
+			return level
+		}
+
+		level := 0
+		for _, dependencyId := range selectedDependencies(graph, id, selected) {
+			if l := getLevel(dependencyId) + 1; l > level {
+				level = l
+			}
+		}
+
+		levels[id] = level
+		return level
+	}
+
+	// The index of the entry of each component in each stack
+	entries := map[string]int{}
+
+	for _, a := range affected {
+		level := 0
+		if id := dependencyGraphNodeId(a.ComponentType, a.Component, a.Stack); selected[id] {
+			level = getLevel(id)
+		}
+
+		entry := cfg.AffectedMatrixEntry{
+			Component:       a.Component,
+			ComponentType:   a.ComponentType,
+			ComponentPath:   a.ComponentPath,
+			Stack:           a.Stack,
+			StackSlug:       a.StackSlug,
+			SpaceliftStack:  a.SpaceliftStack,
+			AtlantisProject: a.AtlantisProject,
+			Affected:        a.Affected,
+			Level:           level,
+		}
+
+		key := a.Stack + "/" + a.Component
+		if i, ok := entries[key]; ok {
+			if level < matrix.Include[i].Level {
+				matrix.Include[i] = entry
+			}
+			continue
+		}
+
+		entries[key] = len(matrix.Include)
+		matrix.Include = append(matrix.Include, entry)
+	}
+
+	sort.SliceStable(matrix.Include, func(i, j int) bool {
+		a, b := matrix.Include[i], matrix.Include[j]
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		if a.Stack != b.Stack {
+			return a.Stack < b.Stack
+		}
+		return a.Component < b.Component
+	})
+
+	return matrix, nil
+}
+
+// printOrWriteAffectedMatrix prints the matrix as a single-line JSON document (as required by GitHub Actions outputs),
+// or writes it to the file
+func printOrWriteAffectedMatrix(file string, matrix cfg.AffectedMatrix) error {
+	j, err := u.ConvertToJSONFast(matrix)
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		fmt.Println(j)
+		return nil
+	}
+
+	return os.WriteFile(file, []byte(j+"\n"), 0644)
+}
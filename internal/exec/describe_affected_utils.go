@@ -6,12 +6,14 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/pkg/errors"
 
@@ -25,7 +27,7 @@ var (
 )
 
 // ExecuteDescribeAffectedWithTargetRepoClone clones the remote repo using `ref` or `sha`, processes stack configs
-// and returns a list of the affected Atmos components and stacks given two Git commits
+// and returns a list of the affected Atmos components and stacks given two Git commits, and the current stacks
 func ExecuteDescribeAffectedWithTargetRepoClone(
 	cliConfig cfg.CliConfiguration,
 	ref string,
@@ -33,25 +35,27 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 	sshKeyPath string,
 	sshKeyPassword string,
 	verbose bool,
-) ([]cfg.Affected, error) {
+	includeDependants bool,
+	includeDiff bool,
+) ([]cfg.Affected, map[string]any, error) {
 
 	localRepo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{
 		DetectDotGit:          true,
 		EnableDotGitCommonDir: false,
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
 	}
 
 	// Get the Git config of the local repo
 	localRepoConfig, err := localRepo.Config()
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
 	}
 
 	localRepoWorktree, err := localRepo.Worktree()
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
 	}
 
 	localRepoPath := localRepoWorktree.Filesystem.Root()
@@ -63,18 +67,18 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 	}
 
 	if len(keys) == 0 {
-		return nil, localRepoIsNotGitRepoError
+		return nil, nil, localRepoIsNotGitRepoError
 	}
 
 	// Get the origin URL of the current remoteRepo
 	remoteUrls := localRepoConfig.Remotes[keys[0]].URLs
 	if len(remoteUrls) == 0 {
-		return nil, localRepoIsNotGitRepoError
+		return nil, nil, localRepoIsNotGitRepoError
 	}
 
 	repoUrl := remoteUrls[0]
 	if repoUrl == "" {
-		return nil, localRepoIsNotGitRepoError
+		return nil, nil, localRepoIsNotGitRepoError
 	}
 
 	// Clone the remote repo
@@ -89,7 +93,7 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 	// Create a temp dir to clone the remote repo to
 	tempDir, err := os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer removeTempDir(tempDir)
@@ -120,12 +124,12 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 	if sshKeyPath != "" {
 		sshKeyContent, err := os.ReadFile(sshKeyPath)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		sshPublicKey, err := ssh.NewPublicKeys("git", sshKeyContent, sshKeyPassword)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Use the SSH key to clone the repo
@@ -138,12 +142,12 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 
 	remoteRepo, err := git.PlainClone(tempDir, false, &cloneOptions)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	remoteRepoHead, err := remoteRepo.Head()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if ref != "" {
@@ -158,7 +162,7 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 
 		w, err := remoteRepo.Worktree()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		checkoutOptions := git.CheckoutOptions{
@@ -170,45 +174,53 @@ func ExecuteDescribeAffectedWithTargetRepoClone(
 
 		err = w.Checkout(&checkoutOptions)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		u.PrintInfoVerbose(verbose, fmt.Sprintf("\nChecked out commit SHA '%s'\n", sha))
 	}
 
-	affected, err := executeDescribeAffected(cliConfig, localRepoPath, tempDir, localRepo, remoteRepo, verbose)
+	// Get the HEAD again since the commit SHA could be checked out
+	remoteRepoHead, err = remoteRepo.Head()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return affected, nil
+	affected, currentStacks, err := executeDescribeAffected(cliConfig, localRepoPath, tempDir, localRepo, remoteRepo, remoteRepoHead, verbose, includeDependants, includeDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return affected, currentStacks, nil
 }
 
 // ExecuteDescribeAffectedWithTargetRepoPath uses `repo-path` to access the target repo, processes stack configs
-// and returns a list of the affected Atmos components and stacks given two Git commits
+// and returns a list of the affected Atmos components and stacks given two Git commits, and the current stacks
 func ExecuteDescribeAffectedWithTargetRepoPath(
 	cliConfig cfg.CliConfiguration,
 	repoPath string,
 	verbose bool,
-) ([]cfg.Affected, error) {
+	includeDependants bool,
+	includeDiff bool,
+) ([]cfg.Affected, map[string]any, error) {
 
 	localRepo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{
 		DetectDotGit:          true,
 		EnableDotGitCommonDir: false,
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
 	}
 
 	// Check the Git config of the local repo
 	_, err = localRepo.Config()
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
 	}
 
 	localRepoWorktree, err := localRepo.Worktree()
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
 	}
 
 	localRepoPath := localRepoWorktree.Filesystem.Root()
@@ -218,21 +230,144 @@ func ExecuteDescribeAffectedWithTargetRepoPath(
 		EnableDotGitCommonDir: false,
 	})
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", remoteRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", remoteRepoIsNotGitRepoError)
 	}
 
 	// Check the Git config of the remote target repo
 	_, err = remoteRepo.Config()
 	if err != nil {
-		return nil, errors.Wrapf(err, "%v", remoteRepoIsNotGitRepoError)
+		return nil, nil, errors.Wrapf(err, "%v", remoteRepoIsNotGitRepoError)
 	}
 
-	affected, err := executeDescribeAffected(cliConfig, localRepoPath, repoPath, localRepo, remoteRepo, verbose)
+	remoteRepoHead, err := remoteRepo.Head()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return affected, nil
+	affected, currentStacks, err := executeDescribeAffected(cliConfig, localRepoPath, repoPath, localRepo, remoteRepo, remoteRepoHead, verbose, includeDependants, includeDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return affected, currentStacks, nil
+}
+
+// ExecuteDescribeAffectedWithBase reads the target commit `base` (SHA or Git reference) from the local Git repository,
+// processes stack configs and returns a list of the affected Atmos components and stacks given two Git commits, and the current stacks.
+// The target repo is not cloned, and the network access is not required, but the `base` commit must exist in the local repo
+func ExecuteDescribeAffectedWithBase(
+	cliConfig cfg.CliConfiguration,
+	base string,
+	verbose bool,
+	includeDependants bool,
+	includeDiff bool,
+) ([]cfg.Affected, map[string]any, error) {
+
+	localRepo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: false,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+	}
+
+	localRepoWorktree, err := localRepo.Worktree()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "%v", localRepoIsNotGitRepoError)
+	}
+
+	localRepoPath := localRepoWorktree.Filesystem.Root()
+
+	baseHash, err := localRepo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "the base '%s' is not found in the local repo. Make sure the commit is fetched (e.g. 'git fetch origin %s')", base, base)
+	}
+
+	baseCommit, err := localRepo.CommitObject(*baseHash)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "the base commit '%s' is not found in the local repo. Make sure the commit is fetched (e.g. 'git fetch origin %s')", baseHash, base)
+	}
+
+	localRepoPathAbs, err := filepath.Abs(localRepoPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	basePath, err := getRepoRelativeBasePath(cliConfig, localRepoPathAbs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create a temp dir and write the stack config files from the base commit to it
+	tempDir, err := os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer removeTempDir(tempDir)
+
+	u.PrintInfoVerbose(verbose, fmt.Sprintf("\nWriting the stack config files from the base commit '%s' into the temp dir '%s'", baseHash, tempDir))
+
+	err = writeCommitFilesToDir(baseCommit, path.Join(basePath, cliConfig.Stacks.BasePath), tempDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	baseRef := plumbing.NewHashReference(plumbing.ReferenceName(base), *baseHash)
+
+	affected, currentStacks, err := executeDescribeAffected(cliConfig, localRepoPath, tempDir, localRepo, localRepo, baseRef, verbose, includeDependants, includeDiff)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return affected, currentStacks, nil
+}
+
+// writeCommitFilesToDir writes the files from the Git commit located in the `pathPrefix` folder (relative to the repo root) to the dir
+func writeCommitFilesToDir(commit *object.Commit, pathPrefix string, dir string) error {
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	pathPrefix = path.Clean(pathPrefix)
+
+	return tree.Files().ForEach(func(f *object.File) error {
+		if !f.Mode.IsFile() {
+			return nil
+		}
+
+		if pathPrefix != "." && !strings.HasPrefix(f.Name, pathPrefix+"/") {
+			return nil
+		}
+
+		content, err := f.Contents()
+		if err != nil {
+			return err
+		}
+
+		filePath := filepath.Join(dir, filepath.FromSlash(f.Name))
+
+		if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+
+		return os.WriteFile(filePath, []byte(content), 0644)
+	})
+}
+
+// getRepoRelativeBasePath returns the `atmos` base path relative to the local repo root.
+// Absolute base path can be set in the `base_path` attribute in `atmos.yaml`, or using the ENV var `ATMOS_BASE_PATH` (as it's done in `geodesic`)
+// If the `atmos` base path is absolute, find the relative path between the local repo path and the `atmos` base path.
+// This relative path (the difference) is then used to join with the remote (cloned) repo path
+func getRepoRelativeBasePath(cliConfig cfg.CliConfiguration, localRepoFileSystemPathAbs string) (string, error) {
+	basePath := cliConfig.BasePath
+
+	if path.IsAbs(basePath) {
+		return filepath.Rel(localRepoFileSystemPathAbs, basePath)
+	}
+
+	return basePath, nil
 }
 
 func executeDescribeAffected(
@@ -241,17 +376,15 @@ func executeDescribeAffected(
 	remoteRepoFileSystemPath string,
 	localRepo *git.Repository,
 	remoteRepo *git.Repository,
+	remoteRepoHead *plumbing.Reference,
 	verbose bool,
-) ([]cfg.Affected, error) {
+	includeDependants bool,
+	includeDiff bool,
+) ([]cfg.Affected, map[string]any, error) {
 
 	localRepoHead, err := localRepo.Head()
 	if err != nil {
-		return nil, err
-	}
-
-	remoteRepoHead, err := remoteRepo.Head()
-	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if verbose {
@@ -261,25 +394,17 @@ func executeDescribeAffected(
 
 	currentStacks, err := ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, false)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	localRepoFileSystemPathAbs, err := filepath.Abs(localRepoFileSystemPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	basePath := cliConfig.BasePath
-
-	// Handle `atmos` absolute base path.
-	// Absolute base path can be set in the `base_path` attribute in `atmos.yaml`, or using the ENV var `ATMOS_BASE_PATH` (as it's done in `geodesic`)
-	// If the `atmos` base path is absolute, find the relative path between the local repo path and the `atmos` base path.
-	// This relative path (the difference) is then used below to join with the remote (cloned) repo path.
-	if path.IsAbs(basePath) {
-		basePath, err = filepath.Rel(localRepoFileSystemPathAbs, basePath)
-		if err != nil {
-			return nil, err
-		}
+	basePath, err := getRepoRelativeBasePath(cliConfig, localRepoFileSystemPathAbs)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Update paths to point to the cloned remote repo dir
@@ -292,19 +417,19 @@ func executeDescribeAffected(
 		cliConfig.StackConfigFilesRelativePaths,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	remoteStacks, err := ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, true)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	u.PrintInfoVerbose(verbose, fmt.Sprintf("\nGetting current working repo commit object..."))
 
 	localCommit, err := localRepo.CommitObject(localRepoHead.Hash())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	u.PrintInfoVerbose(verbose, fmt.Sprintf("Got current working repo commit object"))
@@ -312,7 +437,7 @@ func executeDescribeAffected(
 
 	localTree, err := localCommit.Tree()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	u.PrintInfoVerbose(verbose, fmt.Sprintf("Got current working repo commit tree"))
@@ -320,7 +445,7 @@ func executeDescribeAffected(
 
 	remoteCommit, err := remoteRepo.CommitObject(remoteRepoHead.Hash())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	u.PrintInfoVerbose(verbose, fmt.Sprintf("Got remote repo commit object"))
@@ -328,7 +453,7 @@ func executeDescribeAffected(
 
 	remoteTree, err := remoteCommit.Tree()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	u.PrintInfoVerbose(verbose, fmt.Sprintf("Got remote repo commit tree"))
@@ -337,7 +462,7 @@ func executeDescribeAffected(
 	// Find a slice of Patch objects with all the changes between the current working and remote trees
 	patch, err := localTree.Patch(remoteTree)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	u.PrintInfoVerbose(verbose, fmt.Sprintf("Found diff between the current working branch and remote target branch"))
@@ -351,12 +476,29 @@ func executeDescribeAffected(
 
 	u.PrintMessageVerbose(verbose, "")
 
-	affected, err := findAffected(currentStacks, remoteStacks, cliConfig, changedFiles)
+	// The paths to the local modules used by the Terraform components, cached per component folder
+	terraformModulePaths := map[string][]string{}
+
+	affected, err := findAffected(currentStacks, remoteStacks, cliConfig, changedFiles, terraformModulePaths)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return affected, nil
+	if includeDiff {
+		affected, err = addDiffToAffected(currentStacks, remoteStacks, cliConfig, changedFiles, terraformModulePaths, affected)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if includeDependants {
+		affected, err = AddDependantsToAffected(cliConfig, currentStacks, affected)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return affected, currentStacks, nil
 }
 
 // findAffected returns a list of all affected components in all stacks
@@ -365,6 +507,7 @@ func findAffected(
 	remoteStacks map[string]any,
 	cliConfig cfg.CliConfiguration,
 	changedFiles []string,
+	terraformModulePaths map[string][]string,
 ) ([]cfg.Affected, error) {
 
 	res := []cfg.Affected{}
@@ -413,6 +556,24 @@ func findAffected(
 									}
 									continue
 								}
+								// Check if any files in the local Terraform modules used by the component have changed
+								changed, err := areTerraformComponentModulesChanged(component, cliConfig, changedFiles, terraformModulePaths)
+								if err != nil {
+									return nil, err
+								}
+								if changed {
+									affected := cfg.Affected{
+										ComponentType: "terraform",
+										Component:     componentName,
+										Stack:         stackName,
+										Affected:      "component.module",
+									}
+									res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+									if err != nil {
+										return nil, err
+									}
+									continue
+								}
 							}
 							// Check `vars` section
 							if varSection, ok := componentSection["vars"].(map[any]any); ok {
@@ -462,6 +623,76 @@ func findAffected(
 									continue
 								}
 							}
+							// Check `backend_type` section
+							if isSectionChanged(remoteStacks, stackName, "terraform", componentName, componentSection, "backend_type") {
+								affected := cfg.Affected{
+									ComponentType: "terraform",
+									Component:     componentName,
+									Stack:         stackName,
+									Affected:      "stack.backend_type",
+								}
+								res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+								if err != nil {
+									return nil, err
+								}
+								continue
+							}
+							// Check `backend` section
+							if isSectionChanged(remoteStacks, stackName, "terraform", componentName, componentSection, "backend") {
+								affected := cfg.Affected{
+									ComponentType: "terraform",
+									Component:     componentName,
+									Stack:         stackName,
+									Affected:      "stack.backend",
+								}
+								res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+								if err != nil {
+									return nil, err
+								}
+								continue
+							}
+							// Check `remote_state_backend_type` section
+							if isSectionChanged(remoteStacks, stackName, "terraform", componentName, componentSection, "remote_state_backend_type") {
+								affected := cfg.Affected{
+									ComponentType: "terraform",
+									Component:     componentName,
+									Stack:         stackName,
+									Affected:      "stack.remote_state_backend_type",
+								}
+								res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+								if err != nil {
+									return nil, err
+								}
+								continue
+							}
+							// Check `remote_state_backend` section
+							if isSectionChanged(remoteStacks, stackName, "terraform", componentName, componentSection, "remote_state_backend") {
+								affected := cfg.Affected{
+									ComponentType: "terraform",
+									Component:     componentName,
+									Stack:         stackName,
+									Affected:      "stack.remote_state_backend",
+								}
+								res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+								if err != nil {
+									return nil, err
+								}
+								continue
+							}
+							// Check `command` section
+							if isSectionChanged(remoteStacks, stackName, "terraform", componentName, componentSection, "command") {
+								affected := cfg.Affected{
+									ComponentType: "terraform",
+									Component:     componentName,
+									Stack:         stackName,
+									Affected:      "stack.command",
+								}
+								res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+								if err != nil {
+									return nil, err
+								}
+								continue
+							}
 						}
 					}
 				}
@@ -555,6 +786,20 @@ func findAffected(
 									continue
 								}
 							}
+							// Check `command` section
+							if isSectionChanged(remoteStacks, stackName, "helmfile", componentName, componentSection, "command") {
+								affected := cfg.Affected{
+									ComponentType: "helmfile",
+									Component:     componentName,
+									Stack:         stackName,
+									Affected:      "stack.command",
+								}
+								res, err = appendToAffected(cliConfig, componentName, stackName, componentSection, res, affected)
+								if err != nil {
+									return nil, err
+								}
+								continue
+							}
 						}
 					}
 				}
@@ -624,32 +869,153 @@ func appendToAffected(
 	return append(affectedList, affected), nil
 }
 
+// AddDependantsToAffected adds the components that depend on the affected components (directly or transitively)
+// to the affected list. The dependencies are defined in the `settings.depends_on` sections of the components in the stacks
+// (the output of `ExecuteDescribeStacks`). The added items are marked with `affected: dependant`,
+// and the `dependant_of` field is set to the stack slug of the affected component that the dependant depends on (directly or transitively).
+// If the dependant depends on more than one affected component, the first of them (sorted by stack, component and component type) is used
+func AddDependantsToAffected(
+	cliConfig cfg.CliConfiguration,
+	stacks map[string]any,
+	affected []cfg.Affected,
+) ([]cfg.Affected, error) {
+
+	graph, err := buildDependencyGraph(cliConfig, stacks)
+	if err != nil {
+		return nil, err
+	}
+
+	res := affected
+	triggers := map[string]bool{}
+	added := map[string]bool{}
+
+	for _, a := range affected {
+		id := dependencyGraphNodeId(a.ComponentType, a.Component, a.Stack)
+		triggers[id] = true
+		added[id] = true
+	}
+
+	// Process the affected components in the order of the graph nodes (sorted by stack, component and component type),
+	// so that the result does not depend on the order of the affected list
+	for _, triggerId := range graph.ids {
+		if !triggers[triggerId] {
+			continue
+		}
+
+		node := graph.nodes[triggerId]
+		queue := append([]string{}, node.Dependants...)
+
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+
+			if added[id] {
+				continue
+			}
+			added[id] = true
+
+			dependant := graph.nodes[id]
+
+			componentSection, ok := getStackComponentSection(stacks, dependant.Stack, dependant.ComponentType, dependant.Component)
+			if !ok {
+				continue
+			}
+
+			item := cfg.Affected{
+				ComponentType: dependant.ComponentType,
+				Component:     dependant.Component,
+				Stack:         dependant.Stack,
+				Affected:      "dependant",
+				DependantOf:   dependencyGraphNodeStackSlug(node),
+			}
+
+			res, err = appendToAffected(cliConfig, dependant.Component, dependant.Stack, componentSection, res, item)
+			if err != nil {
+				return nil, err
+			}
+
+			queue = append(queue, dependant.Dependants...)
+		}
+	}
+
+	return res, nil
+}
+
+// getStackComponentSection returns the section of the component in the stack from the output of `ExecuteDescribeStacks`
+func getStackComponentSection(stacks map[string]any, stack string, componentType string, component string) (map[string]any, bool) {
+	stackSection, ok := stacks[stack].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	componentsSection, ok := stackSection["components"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	componentTypeSection, ok := componentsSection[componentType].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+
+	componentSection, ok := componentTypeSection[component].(map[string]any)
+	return componentSection, ok
+}
+
+// isSectionChanged checks if a section of a local component was added, removed or modified compared to the section of the component
+// from the remote stacks
+func isSectionChanged(
+	remoteStacks map[string]any,
+	localStackName string,
+	componentType string,
+	localComponentName string,
+	localComponentSection map[string]any,
+	sectionName string,
+) bool {
+
+	localSection, localOk := localComponentSection[sectionName]
+	remoteSection, remoteOk := getRemoteComponentSection(remoteStacks, localStackName, componentType, localComponentName, sectionName)
+
+	if localOk != remoteOk {
+		return true
+	}
+	return !reflect.DeepEqual(localSection, remoteSection)
+}
+
 // isEqual compares a section of a component from the remote stacks with a section of a local component
 func isEqual(
 	remoteStacks map[string]any,
 	localStackName string,
 	componentType string,
 	localComponentName string,
-	localSection map[any]any,
+	localSection any,
 	sectionName string,
 ) bool {
 
-	if remoteStackSection, ok := remoteStacks[localStackName].(map[string]any); ok {
-		if remoteComponentsSection, ok := remoteStackSection["components"].(map[string]any); ok {
-			if remoteComponentTypeSection, ok := remoteComponentsSection[componentType].(map[string]any); ok {
-				if remoteComponentSection, ok := remoteComponentTypeSection[localComponentName].(map[string]any); ok {
-					if remoteSection, ok := remoteComponentSection[sectionName].(map[any]any); ok {
-						if reflect.DeepEqual(localSection, remoteSection) {
-							return true
-						}
-					}
-				}
-			}
+	if remoteSection, ok := getRemoteComponentSection(remoteStacks, localStackName, componentType, localComponentName, sectionName); ok {
+		if reflect.DeepEqual(localSection, remoteSection) {
+			return true
 		}
 	}
 	return false
 }
 
+// getRemoteComponentSection returns a section of a component from the remote stacks
+func getRemoteComponentSection(
+	remoteStacks map[string]any,
+	stackName string,
+	componentType string,
+	componentName string,
+	sectionName string,
+) (any, bool) {
+
+	if remoteComponentSection, ok := getStackComponentSection(remoteStacks, stackName, componentType, componentName); ok {
+		remoteSection, ok := remoteComponentSection[sectionName]
+		return remoteSection, ok
+	}
+	return nil, false
+}
+
 // isComponentFolderChanged checks if a component folder changed (has changed files in it)
 func isComponentFolderChanged(
 	component string,
@@ -658,17 +1024,189 @@ func isComponentFolderChanged(
 	changedFiles []string,
 ) bool {
 
-	var pathPrefix string
+	pathPrefix := getComponentFolderPath(component, componentType, cliConfig)
+
+	if u.SliceOfPathsContainsPath(changedFiles, pathPrefix) {
+		return true
+	}
+	return false
+}
+
+// areTerraformComponentModulesChanged checks if any files in the local modules used by the Terraform component
+// (directly, or by other local modules) or in the subfolders of the modules have changed
+func areTerraformComponentModulesChanged(
+	component string,
+	cliConfig cfg.CliConfiguration,
+	changedFiles []string,
+	terraformModulePaths map[string][]string,
+) (bool, error) {
+
+	modulePaths, err := getTerraformComponentModulePaths(getComponentFolderPath(component, "terraform", cliConfig), terraformModulePaths)
+	if err != nil {
+		return false, err
+	}
+
+	for _, modulePath := range modulePaths {
+		if u.SliceOfPathsContainsPathInDir(changedFiles, modulePath) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getTerraformComponentModulePaths returns the paths to the local modules used by the Terraform component in the folder.
+// The same component is usually provisioned in many stacks, so the paths are cached per component folder
+func getTerraformComponentModulePaths(componentPath string, terraformModulePaths map[string][]string) ([]string, error) {
+	if modulePaths, ok := terraformModulePaths[componentPath]; ok {
+		return modulePaths, nil
+	}
+
+	modulePaths, err := u.GetTerraformLocalModulePaths(componentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	terraformModulePaths[componentPath] = modulePaths
+	return modulePaths, nil
+}
+
+// addDiffToAffected adds the details of the changes to the affected list:
+// the changed files for the affected components and modules, and the key-level diff for the affected stack config sections
+func addDiffToAffected(
+	currentStacks map[string]any,
+	remoteStacks map[string]any,
+	cliConfig cfg.CliConfiguration,
+	changedFiles []string,
+	terraformModulePaths map[string][]string,
+	affected []cfg.Affected,
+) ([]cfg.Affected, error) {
+
+	for i := range affected {
+		item := &affected[i]
+
+		componentSection, ok := getStackComponentSection(currentStacks, item.Stack, item.ComponentType, item.Component)
+		if !ok {
+			continue
+		}
+
+		component, _ := componentSection["component"].(string)
+
+		switch {
+		case item.Affected == "component":
+			item.ChangedFiles = findChangedFilesInFolders(changedFiles, []string{getComponentFolderPath(component, item.ComponentType, cliConfig)})
+
+		case item.Affected == "component.module":
+			modulePaths, err := getTerraformComponentModulePaths(getComponentFolderPath(component, item.ComponentType, cliConfig), terraformModulePaths)
+			if err != nil {
+				return nil, err
+			}
+			item.ChangedFiles = findChangedFilesInFoldersRecursively(changedFiles, modulePaths)
+
+		case strings.HasPrefix(item.Affected, "stack."):
+			sectionName := strings.TrimPrefix(item.Affected, "stack.")
+			localSection := componentSection[sectionName]
+			remoteSection, _ := getRemoteComponentSection(remoteStacks, item.Stack, item.ComponentType, item.Component, sectionName)
+
+			// If the section does not exist in the remote stacks, all the keys of the local section are added.
+			// If the section was removed from the local stacks, all the keys of the remote section are removed
+			if _, isMap := localSection.(map[any]any); isMap && remoteSection == nil {
+				remoteSection = map[any]any{}
+			}
+			if _, isMap := remoteSection.(map[any]any); isMap && localSection == nil {
+				localSection = map[any]any{}
+			}
+
+			diff := &cfg.AffectedDiff{}
+			diffComponentSection(sectionName, remoteSection, localSection, diff)
+			item.Diff = diff
+		}
+	}
+
+	return affected, nil
+}
 
+// getComponentFolderPath returns the path to the folder of the Terraform or Helmfile component (the same as the paths of the changed files)
+func getComponentFolderPath(component string, componentType string, cliConfig cfg.CliConfiguration) string {
 	switch componentType {
 	case "terraform":
-		pathPrefix = path.Join(cliConfig.BasePath, cliConfig.Components.Terraform.BasePath, component)
+		return path.Join(cliConfig.BasePath, cliConfig.Components.Terraform.BasePath, component)
 	case "helmfile":
-		pathPrefix = path.Join(cliConfig.BasePath, cliConfig.Components.Helmfile.BasePath, component)
+		return path.Join(cliConfig.BasePath, cliConfig.Components.Helmfile.BasePath, component)
 	}
+	return ""
+}
 
-	if u.SliceOfPathsContainsPath(changedFiles, pathPrefix) {
-		return true
+// findChangedFilesInFolders returns the changed files located directly in any of the folders
+func findChangedFilesInFolders(changedFiles []string, folders []string) []string {
+	var res []string
+	for _, file := range changedFiles {
+		if u.SliceContainsString(folders, path.Dir(file)) {
+			res = append(res, file)
+		}
+	}
+	return res
+}
+
+// findChangedFilesInFoldersRecursively returns the changed files located in any of the folders or in their subfolders
+func findChangedFilesInFoldersRecursively(changedFiles []string, folders []string) []string {
+	var res []string
+	for _, file := range changedFiles {
+		for _, folder := range folders {
+			if u.IsPathInDir(file, folder) {
+				res = append(res, file)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// diffComponentSection compares the old (remote) and the new (local) values of a component section recursively,
+// and adds the added, removed and changed keys to the diff. Lists are compared as a whole
+func diffComponentSection(keyPath string, oldValue any, newValue any, diff *cfg.AffectedDiff) {
+	oldMap, oldIsMap := oldValue.(map[any]any)
+	newMap, newIsMap := newValue.(map[any]any)
+
+	if !oldIsMap || !newIsMap {
+		if !reflect.DeepEqual(oldValue, newValue) {
+			diff.Changed = append(diff.Changed, cfg.AffectedDiffItem{Path: keyPath, OldValue: oldValue, NewValue: newValue})
+		}
+		return
+	}
+
+	oldValues := map[string]any{}
+	newValues := map[string]any{}
+	var keys []string
+
+	for k, v := range oldMap {
+		key := fmt.Sprintf("%v", k)
+		oldValues[key] = v
+		keys = append(keys, key)
+	}
+
+	for k, v := range newMap {
+		key := fmt.Sprintf("%v", k)
+		newValues[key] = v
+		if _, ok := oldValues[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldItem, inOld := oldValues[key]
+		newItem, inNew := newValues[key]
+		itemPath := keyPath + "." + key
+
+		switch {
+		case !inOld:
+			diff.Added = append(diff.Added, cfg.AffectedDiffItem{Path: itemPath, NewValue: newItem})
+		case !inNew:
+			diff.Removed = append(diff.Removed, cfg.AffectedDiffItem{Path: itemPath, OldValue: oldItem})
+		default:
+			diffComponentSection(itemPath, oldItem, newItem, diff)
+		}
 	}
-	return false
 }
@@ -1,10 +1,17 @@
 package exec
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	yaml3 "gopkg.in/yaml.v3"
 
 	cfg "github.com/cloudposse/atmos/pkg/config"
+	s "github.com/cloudposse/atmos/pkg/stack"
 	u "github.com/cloudposse/atmos/pkg/utils"
 )
 
@@ -31,8 +38,21 @@ func ExecuteDescribeComponentCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	provenance, err := flags.GetBool("provenance")
+	if err != nil {
+		return err
+	}
+
 	component := args[0]
 
+	if provenance {
+		componentSection, componentProvenance, err := ExecuteDescribeComponentWithProvenance(component, stack)
+		if err != nil {
+			return err
+		}
+		return printOrWriteComponentWithProvenance(format, file, componentSection, componentProvenance)
+	}
+
 	componentSection, err := ExecuteDescribeComponent(component, stack)
 	if err != nil {
 		return err
@@ -48,13 +68,69 @@ func ExecuteDescribeComponentCmd(cmd *cobra.Command, args []string) error {
 
 // ExecuteDescribeComponent describes component config
 func ExecuteDescribeComponent(component string, stack string) (map[string]any, error) {
+	_, configAndStacksInfo, err := describeComponent(component, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	return configAndStacksInfo.ComponentSection, nil
+}
+
+// ExecuteDescribeComponentWithProvenance describes component config, and returns the provenance
+// (the stack config files, lines and scopes where the values were set) of all the leaf values
+// in the `vars`, `settings`, `env` and `backend` sections of the component
+func ExecuteDescribeComponentWithProvenance(component string, stack string) (map[string]any, cfg.Provenance, error) {
+	cliConfig, configAndStacksInfo, err := describeComponent(component, stack)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stackFilePath := ""
+	for _, p := range cliConfig.StackConfigFilesAbsolutePaths {
+		if stackConfigFileName(cliConfig.StacksBaseAbsolutePath, p) == configAndStacksInfo.StackFile {
+			stackFilePath = p
+			break
+		}
+	}
+
+	if stackFilePath == "" {
+		return nil, nil, fmt.Errorf("could not find the stack config file '%s' for the stack '%s'", configAndStacksInfo.StackFile, stack)
+	}
+
+	// Process the stack config file and all its imports again, recording where the values are set
+	stackProvenance := cfg.Provenance{}
+	_, _, _, err = s.ProcessYAMLConfigFileWithProvenance(
+		cliConfig,
+		cliConfig.StacksBaseAbsolutePath,
+		stackFilePath,
+		map[string]map[any]any{},
+		nil,
+		false,
+		stackProvenance,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	componentProvenance := s.FindComponentProvenance(
+		stackProvenance,
+		configAndStacksInfo.ComponentType,
+		configAndStacksInfo.ComponentFromArg,
+		configAndStacksInfo.ComponentSection,
+	)
+
+	return configAndStacksInfo.ComponentSection, componentProvenance, nil
+}
+
+// describeComponent processes the stacks and finds the config of the component in the stack
+func describeComponent(component string, stack string) (cfg.CliConfiguration, cfg.ConfigAndStacksInfo, error) {
 	var configAndStacksInfo cfg.ConfigAndStacksInfo
 	configAndStacksInfo.ComponentFromArg = component
 	configAndStacksInfo.Stack = stack
 
 	cliConfig, err := cfg.InitCliConfig(configAndStacksInfo, true)
 	if err != nil {
-		return nil, err
+		return cliConfig, configAndStacksInfo, err
 	}
 
 	configAndStacksInfo.ComponentType = "terraform"
@@ -64,7 +140,7 @@ func ExecuteDescribeComponent(component string, stack string) (map[string]any, e
 		configAndStacksInfo.ComponentType = "helmfile"
 		configAndStacksInfo, err = ProcessStacks(cliConfig, configAndStacksInfo, true)
 		if err != nil {
-			return nil, err
+			return cliConfig, configAndStacksInfo, err
 		}
 	}
 
@@ -77,5 +153,109 @@ func ExecuteDescribeComponent(component string, stack string) (map[string]any, e
 		configAndStacksInfo.ComponentSection["component"] = configAndStacksInfo.ComponentFromArg
 	}
 
-	return configAndStacksInfo.ComponentSection, nil
+	return cliConfig, configAndStacksInfo, nil
+}
+
+// stackConfigFileName returns the name of the stack config file relative to the stacks base path and without extension
+// (the same as the stack names returned from `ProcessYAMLConfigFiles`)
+func stackConfigFileName(stacksBasePath string, filePath string) string {
+	return strings.TrimSuffix(
+		strings.TrimSuffix(
+			u.TrimBasePathFromPath(stacksBasePath+"/", filePath),
+			cfg.DefaultStackConfigFileExtension),
+		".yml",
+	)
+}
+
+// printOrWriteComponentWithProvenance prints the component config with the provenance of the values, or writes it to the file.
+// In YAML format, the provenance is added as a comment to each value.
+// In JSON format, the provenance is added to the `provenance` section
+func printOrWriteComponentWithProvenance(format string, file string, componentSection map[string]any, provenance cfg.Provenance) error {
+	if format != "yaml" {
+		result := map[string]any{}
+		for k, v := range componentSection {
+			result[k] = v
+		}
+		result["provenance"] = provenance
+		return printOrWriteToFile(format, file, result)
+	}
+
+	var node yaml3.Node
+	if err := node.Encode(componentSection); err != nil {
+		return err
+	}
+
+	// The root node is the map of the component sections
+	addProvenanceComments(&node, nil, provenance)
+
+	var buf bytes.Buffer
+	encoder := yaml3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	if file == "" {
+		fmt.Print(buf.String())
+		return nil
+	}
+
+	return os.WriteFile(file, buf.Bytes(), 0644)
+}
+
+// addProvenanceComments adds the provenance of the values as line comments to the YAML nodes.
+// The comment starts with the place where the final value was set, followed by the places where the overridden values were set
+func addProvenanceComments(node *yaml3.Node, keyPath []string, provenance cfg.Provenance) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+
+		itemPath := make([]string, len(keyPath), len(keyPath)+1)
+		copy(itemPath, keyPath)
+		itemPath = append(itemPath, keyNode.Value)
+
+		if valueNode.Kind == yaml3.MappingNode {
+			addProvenanceComments(valueNode, itemPath, provenance)
+			continue
+		}
+
+		comment := provenanceComment(provenance[s.ProvenanceKey(itemPath)])
+		if comment == "" {
+			continue
+		}
+
+		// The comments of the lists are added to the keys, otherwise they are printed after the last item of the list
+		if valueNode.Kind == yaml3.ScalarNode {
+			valueNode.LineComment = comment
+		} else {
+			keyNode.LineComment = comment
+		}
+	}
+}
+
+// provenanceComment formats the provenance of a value as a comment
+func provenanceComment(entries []cfg.ProvenanceEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var places []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		scope := entry.Scope
+		if entry.BaseComponent != "" {
+			scope = fmt.Sprintf("%s %s", scope, entry.BaseComponent)
+		}
+		places = append(places, fmt.Sprintf("%s:%d [%s]", entry.File, entry.Line, scope))
+	}
+
+	comment := places[0]
+	if len(places) > 1 {
+		comment += ", overrides: " + strings.Join(places[1:], ", ")
+	}
+
+	return comment
 }
@@ -45,9 +45,26 @@ func ExecuteDescribeDependantsCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	transitive, err := flags.GetBool("transitive")
+	if err != nil {
+		return err
+	}
+
+	maxDepth, err := flags.GetInt("max-depth")
+	if err != nil {
+		return err
+	}
+
 	component := args[0]
 
-	dependants, err := ExecuteDescribeDependants(cliConfig, component, stack)
+	var dependants []cfg.Dependant
+
+	// `--max-depth` enables the transitive mode
+	if transitive || maxDepth > 0 {
+		dependants, err = ExecuteDescribeDependantsTransitive(cliConfig, component, stack, maxDepth)
+	} else {
+		dependants, err = ExecuteDescribeDependants(cliConfig, component, stack)
+	}
 	if err != nil {
 		return err
 	}
@@ -67,8 +84,32 @@ func ExecuteDescribeDependants(
 	stack string,
 ) ([]cfg.Dependant, error) {
 
+	// Get all stacks with all components
+	stacks, err := ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	currentComponentVars, ok, err := describeComponentContext(component, stack)
+	if err != nil || !ok {
+		return []cfg.Dependant{}, err
+	}
+
+	return findDependants(cliConfig, stacks, component, currentComponentVars)
+}
+
+// ExecuteDescribeDependantsTransitive produces a list of Atmos components in Atmos stacks that depend on the provided Atmos component
+// directly or through other components (the dependants of the dependants, to any depth).
+// Each dependant has the depth (1 for the direct dependants) and the path (the stack slugs of the components from the provided component to the dependant).
+// If `maxDepth` is greater than 0, only the dependants up to the depth are returned
+func ExecuteDescribeDependantsTransitive(
+	cliConfig cfg.CliConfiguration,
+	component string,
+	stack string,
+	maxDepth int,
+) ([]cfg.Dependant, error) {
+
 	dependants := []cfg.Dependant{}
-	var ok bool
 
 	// Get all stacks with all components
 	stacks, err := ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, false)
@@ -76,24 +117,116 @@ func ExecuteDescribeDependants(
 		return nil, err
 	}
 
-	currentComponentSection, err := ExecuteDescribeComponent(component, stack)
+	graph, err := buildDependencyGraph(cliConfig, stacks)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the current component `vars`
-	var currentComponentVarsSection map[any]any
-	if currentComponentVarsSection, ok = currentComponentSection["vars"].(map[any]any); !ok {
-		return dependants, nil
+	type queueItem struct {
+		id    string
+		depth int
+		path  []string
+	}
+
+	var queue []queueItem
+	visited := map[string]bool{}
+
+	// The same component name can be used for a terraform and a helmfile component in the stack
+	for _, id := range graph.ids {
+		node := graph.nodes[id]
+		if node.Component == component && node.Stack == stack {
+			queue = append(queue, queueItem{id: id, depth: 0, path: []string{dependencyGraphNodeStackSlug(node)}})
+			visited[id] = true
+		}
+	}
+
+	if len(queue) == 0 {
+		return nil, fmt.Errorf("could not find the component '%s' in the stack '%s'", component, stack)
 	}
 
-	// Convert the current component `vars` section to the `Context` structure
-	var currentComponentVars cfg.Context
-	err = mapstructure.Decode(currentComponentVarsSection, &currentComponentVars)
+	// Walk the dependency graph breadth-first, so each dependant is returned with the shortest path to it
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		for _, dependantId := range graph.nodes[item.id].Dependants {
+			if visited[dependantId] {
+				continue
+			}
+			visited[dependantId] = true
+
+			node := graph.nodes[dependantId]
+
+			dependant := cfg.Dependant{
+				Component:     node.Component,
+				ComponentPath: node.ComponentPath,
+				ComponentType: node.ComponentType,
+				Stack:         node.Stack,
+				StackSlug:     dependencyGraphNodeStackSlug(node),
+				Namespace:     node.Namespace,
+				Tenant:        node.Tenant,
+				Environment:   node.Environment,
+				Stage:         node.Stage,
+				Depth:         item.depth + 1,
+			}
+			dependant.Path = append(append([]string{}, item.path...), dependant.StackSlug)
+
+			if componentSection, ok := getStackComponentSection(stacks, node.Stack, node.ComponentType, node.Component); ok {
+				settingsSection, _ := componentSection["settings"].(map[any]any)
+				varsSection, _ := componentSection["vars"].(map[any]any)
+				if err = addSpaceliftStackAndAtlantisProjectToDependant(cliConfig, &dependant, settingsSection, varsSection); err != nil {
+					return nil, err
+				}
+			}
+
+			dependants = append(dependants, dependant)
+
+			if maxDepth > 0 && dependant.Depth >= maxDepth {
+				continue
+			}
+
+			queue = append(queue, queueItem{id: dependantId, depth: dependant.Depth, path: dependant.Path})
+		}
+	}
+
+	return dependants, nil
+}
+
+// describeComponentContext returns the context (namespace, tenant, environment, stage) from the `vars` of the component in the stack.
+// It returns `false` if the component does not have the `vars` section
+func describeComponentContext(component string, stack string) (cfg.Context, bool, error) {
+	var componentVars cfg.Context
+
+	componentSection, err := ExecuteDescribeComponent(component, stack)
 	if err != nil {
-		return nil, err
+		return componentVars, false, err
+	}
+
+	componentVarsSection, ok := componentSection["vars"].(map[any]any)
+	if !ok {
+		return componentVars, false, nil
 	}
 
+	// Convert the component `vars` section to the `Context` structure
+	if err = mapstructure.Decode(componentVarsSection, &componentVars); err != nil {
+		return componentVars, false, err
+	}
+
+	return componentVars, true, nil
+}
+
+// findDependants returns the Atmos components in all the stacks that depend on the component with the provided context
+func findDependants(
+	cliConfig cfg.CliConfiguration,
+	stacks map[string]any,
+	component string,
+	currentComponentVars cfg.Context,
+) ([]cfg.Dependant, error) {
+
+	dependants := []cfg.Dependant{}
+	var ok bool
+	var err error
+
 	// Iterate over all stacks and all components in the stacks
 	for stackName, stackSection := range stacks {
 		var stackSectionMap map[string]any
@@ -136,7 +269,7 @@ func ExecuteDescribeDependants(
 				// Get the stack component `vars`
 				var stackComponentVarsSection map[any]any
 				if stackComponentVarsSection, ok = stackComponentMap["vars"].(map[any]any); !ok {
-					return dependants, nil
+					continue
 				}
 
 				// Convert the stack component `vars` section to the `Context` structure
@@ -216,36 +349,9 @@ func ExecuteDescribeDependants(
 					}
 
 					// Add Spacelift stack and Atlantis project if they are configured for the dependant stack component
-					if stackComponentType == "terraform" {
-
-						// Spacelift stack
-						spaceliftStackName, err := BuildSpaceliftStackNameFromComponentConfig(
-							cliConfig,
-							stackComponentName,
-							stackName,
-							stackComponentSettingsSection,
-							stackComponentVarsSection,
-						)
-
-						if err != nil {
-							return nil, err
-						}
-
-						dependant.SpaceliftStack = spaceliftStackName
-
-						// Atlantis project
-						atlantisProjectName, err := BuildAtlantisProjectNameFromComponentConfig(
-							cliConfig,
-							stackComponentName,
-							stackComponentSettingsSection,
-							stackComponentVarsSection,
-						)
-
-						if err != nil {
-							return nil, err
-						}
-
-						dependant.AtlantisProject = atlantisProjectName
+					err = addSpaceliftStackAndAtlantisProjectToDependant(cliConfig, &dependant, stackComponentSettingsSection, stackComponentVarsSection)
+					if err != nil {
+						return nil, err
 					}
 
 					dependants = append(dependants, dependant)
@@ -256,3 +362,47 @@ func ExecuteDescribeDependants(
 
 	return dependants, nil
 }
+
+// addSpaceliftStackAndAtlantisProjectToDependant adds the Spacelift stack and the Atlantis project to the dependant
+// if they are configured for the dependant terraform component
+func addSpaceliftStackAndAtlantisProjectToDependant(
+	cliConfig cfg.CliConfiguration,
+	dependant *cfg.Dependant,
+	settingsSection map[any]any,
+	varsSection map[any]any,
+) error {
+
+	if dependant.ComponentType != "terraform" {
+		return nil
+	}
+
+	// Spacelift stack
+	spaceliftStackName, err := BuildSpaceliftStackNameFromComponentConfig(
+		cliConfig,
+		dependant.Component,
+		dependant.Stack,
+		settingsSection,
+		varsSection,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	dependant.SpaceliftStack = spaceliftStackName
+
+	// Atlantis project
+	atlantisProjectName, err := BuildAtlantisProjectNameFromComponentConfig(
+		cliConfig,
+		dependant.Component,
+		settingsSection,
+		varsSection,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	dependant.AtlantisProject = atlantisProjectName
+	return nil
+}
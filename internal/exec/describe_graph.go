@@ -0,0 +1,463 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+const (
+	graphNodeTypeComponent = "component"
+	graphNodeTypeStack     = "stack"
+	graphNodeTypeImport    = "import"
+
+	graphEdgeTypeDependsOn = "depends_on"
+	graphEdgeTypeInherits  = "inherits"
+	graphEdgeTypeImports   = "imports"
+)
+
+var graphEdgeTypes = []string{graphEdgeTypeDependsOn, graphEdgeTypeInherits, graphEdgeTypeImports}
+
+// ExecuteDescribeGraphCmd executes `describe graph` command
+func ExecuteDescribeGraphCmd(cmd *cobra.Command, args []string) error {
+	info, err := processCommandLineArgs("", cmd, args)
+	if err != nil {
+		return err
+	}
+
+	cliConfig, err := cfg.InitCliConfig(info, true)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+
+	stack, err := flags.GetString("stack")
+	if err != nil {
+		return err
+	}
+
+	componentsCsv, err := flags.GetString("components")
+	if err != nil {
+		return err
+	}
+	var components []string
+	if componentsCsv != "" {
+		components = strings.Split(componentsCsv, ",")
+	}
+
+	edgesCsv, err := flags.GetString("edges")
+	if err != nil {
+		return err
+	}
+	var edgeTypes []string
+	if edgesCsv != "" {
+		edgeTypes = strings.Split(edgesCsv, ",")
+		for _, edgeType := range edgeTypes {
+			if !u.SliceContainsString(graphEdgeTypes, edgeType) {
+				return fmt.Errorf("invalid '--edges' flag '%s'. Valid values are %s", edgeType, strings.Join(graphEdgeTypes, ", "))
+			}
+		}
+	}
+
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+
+	file, err := flags.GetString("file")
+	if err != nil {
+		return err
+	}
+
+	graph, err := ExecuteDescribeGraph(cliConfig, stack, components, edgeTypes)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "", "dot":
+		err = printOrWriteGraph(file, renderGraphDot(graph))
+	case "mermaid":
+		err = printOrWriteGraph(file, renderGraphMermaid(graph))
+	case "json", "yaml":
+		err = printOrWriteToFile(format, file, graph)
+	default:
+		return fmt.Errorf("invalid '--format' flag '%s'. Valid values are 'dot' (default), 'mermaid', 'json' and 'yaml'", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(graph.Cycles) > 0 {
+		var cycles []string
+		for _, cycle := range graph.Cycles {
+			cycles = append(cycles, strings.Join(cycle, ", "))
+		}
+		u.PrintErrorToStdError(fmt.Errorf("found %d dependency cycles between the components:\n%s", len(graph.Cycles), strings.Join(cycles, "\n")))
+	}
+
+	return nil
+}
+
+// ExecuteDescribeGraph returns the graph of the component dependencies (`settings.depends_on`), the component inheritance
+// (`metadata.component` and `metadata.inherits`), and the stack imports (the `deps` of the components in the stacks).
+// If the stack or the components are provided, only the selected components and the components they are directly connected to are returned.
+// The groups of the components that have dependency cycles are returned in the `Cycles` field
+func ExecuteDescribeGraph(
+	cliConfig cfg.CliConfiguration,
+	stack string,
+	components []string,
+	edgeTypes []string,
+) (cfg.Graph, error) {
+
+	graph := cfg.Graph{}
+
+	if len(edgeTypes) == 0 {
+		edgeTypes = graphEdgeTypes
+	}
+
+	stacks, err := ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, false)
+	if err != nil {
+		return graph, err
+	}
+
+	dependencies, err := buildDependencyGraph(cliConfig, stacks)
+	if err != nil {
+		return graph, err
+	}
+
+	nodes := map[string]cfg.GraphNode{}
+	var edges []cfg.GraphEdge
+	// The selected components
+	selected := map[string]bool{}
+	// The imports of the stacks (the `deps` of the selected components)
+	stackImports := map[string]map[string]bool{}
+
+	for stackName, stackSection := range stacks {
+		stackSectionMap, ok := stackSection.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		stackComponentsSection, ok := stackSectionMap["components"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for componentType, componentTypeSection := range stackComponentsSection {
+			componentTypeSectionMap, ok := componentTypeSection.(map[string]any)
+			if !ok {
+				continue
+			}
+
+			for componentName, componentSection := range componentTypeSectionMap {
+				componentSectionMap, ok := componentSection.(map[string]any)
+				if !ok {
+					continue
+				}
+
+				id := graphComponentNodeId(componentType, componentName, stackName)
+				if existing, ok := nodes[id]; ok {
+					return graph, fmt.Errorf("the %s component '%s' in the stack '%s' and the %s component '%s' in the stack '%s' have the same ID '%s' in the graph",
+						existing.ComponentType, existing.Component, existing.Stack, componentType, componentName, stackName, id)
+				}
+
+				node := cfg.GraphNode{
+					Id:            id,
+					Type:          graphNodeTypeComponent,
+					Label:         componentName,
+					Component:     componentName,
+					ComponentType: componentType,
+					Stack:         stackName,
+				}
+
+				var baseComponents []string
+
+				if metadataSection, ok := componentSectionMap["metadata"].(map[any]any); ok {
+					if metadataType, ok := metadataSection["type"].(string); ok && metadataType == "abstract" {
+						node.Abstract = true
+					}
+					if baseComponent, ok := metadataSection["component"].(string); ok && baseComponent != componentName {
+						baseComponents = append(baseComponents, baseComponent)
+					}
+					if inherits, ok := metadataSection["inherits"].([]any); ok {
+						for _, inherit := range inherits {
+							if s, ok := inherit.(string); ok && !u.SliceContainsString(baseComponents, s) {
+								baseComponents = append(baseComponents, s)
+							}
+						}
+					}
+				}
+
+				nodes[id] = node
+
+				for _, baseComponent := range baseComponents {
+					edges = append(edges, cfg.GraphEdge{From: id, To: graphComponentNodeId(componentType, baseComponent, stackName), Type: graphEdgeTypeInherits})
+				}
+
+				if (stack != "" && stack != stackName) || (len(components) > 0 && !u.SliceContainsString(components, componentName)) {
+					continue
+				}
+
+				selected[id] = true
+
+				if deps, ok := componentSectionMap["deps"].([]string); ok {
+					if _, ok := stackImports[stackName]; !ok {
+						stackImports[stackName] = map[string]bool{}
+					}
+					for _, dep := range deps {
+						stackImports[stackName][dep] = true
+					}
+				}
+			}
+		}
+	}
+
+	for _, dependencyNode := range dependencies.nodes {
+		for _, dependencyId := range dependencyNode.DependsOn {
+			edges = append(edges, cfg.GraphEdge{
+				From: graphDependencyNodeId(dependencyNode),
+				To:   graphDependencyNodeId(dependencies.nodes[dependencyId]),
+				Type: graphEdgeTypeDependsOn,
+			})
+		}
+	}
+
+	for stackName, imports := range stackImports {
+		stackId := graphNodeTypeStack + ":" + stackName
+		nodes[stackId] = cfg.GraphNode{Id: stackId, Type: graphNodeTypeStack, Label: stackName, Stack: stackName}
+		selected[stackId] = true
+
+		for imp := range imports {
+			importId := graphNodeTypeImport + ":" + imp
+			nodes[importId] = cfg.GraphNode{Id: importId, Type: graphNodeTypeImport, Label: imp}
+			edges = append(edges, cfg.GraphEdge{From: stackId, To: importId, Type: graphEdgeTypeImports})
+		}
+	}
+
+	// Convert the dependency cycles to the IDs of the graph nodes
+	cycleGroups := map[string]int{}
+	for i, cycle := range findDependencyGraphCycles(dependencies) {
+		var group []string
+		for _, dependencyId := range cycle {
+			id := graphDependencyNodeId(dependencies.nodes[dependencyId])
+			group = append(group, id)
+			cycleGroups[id] = i + 1
+		}
+		graph.Cycles = append(graph.Cycles, group)
+	}
+
+	// Add the edges (of the requested types) that start or end in the selected nodes, and the nodes they connect
+	included := map[string]bool{}
+	for id := range selected {
+		included[id] = true
+	}
+
+	for _, edge := range edges {
+		if !u.SliceContainsString(edgeTypes, edge.Type) {
+			continue
+		}
+		// Skip the edges to the base components that are not defined in the stack (e.g. the terraform component folders)
+		if _, ok := nodes[edge.To]; !ok {
+			continue
+		}
+		if !selected[edge.From] && !selected[edge.To] {
+			continue
+		}
+
+		if edge.Type == graphEdgeTypeDependsOn && cycleGroups[edge.From] != 0 && cycleGroups[edge.From] == cycleGroups[edge.To] {
+			edge.Cycle = true
+		}
+
+		graph.Edges = append(graph.Edges, edge)
+		included[edge.From] = true
+		included[edge.To] = true
+	}
+
+	// Don't show the stacks and imports if the `imports` edges are not requested
+	for id := range included {
+		node := nodes[id]
+		if node.Type != graphNodeTypeComponent && !u.SliceContainsString(edgeTypes, graphEdgeTypeImports) {
+			continue
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	// Only report the cycles of the included components
+	var cycles [][]string
+	for _, cycle := range graph.Cycles {
+		for _, id := range cycle {
+			if included[id] {
+				cycles = append(cycles, cycle)
+				break
+			}
+		}
+	}
+	graph.Cycles = cycles
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Id < graph.Nodes[j].Id
+	})
+
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+
+	return graph, nil
+}
+
+// graphComponentNodeId returns the ID of the graph node for the component of the type in the stack
+func graphComponentNodeId(componentType string, component string, stack string) string {
+	return graphNodeTypeComponent + ":" + dependencyGraphNodeId(componentType, component, stack)
+}
+
+// graphDependencyNodeId returns the ID of the graph node for the node of the dependency graph
+func graphDependencyNodeId(node *cfg.DependencyGraphNode) string {
+	return graphComponentNodeId(node.ComponentType, node.Component, node.Stack)
+}
+
+// printOrWriteGraph prints the rendered graph, or writes it to the file if the file is provided
+func printOrWriteGraph(file string, content string) error {
+	if file == "" {
+		fmt.Print(content)
+		return nil
+	}
+	return os.WriteFile(file, []byte(content), 0644)
+}
+
+// renderGraphDot renders the graph in Graphviz DOT format. The components are grouped by stacks
+func renderGraphDot(graph cfg.Graph) string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph atmos {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	stackComponents := map[string][]cfg.GraphNode{}
+	var stackNames []string
+
+	for _, node := range graph.Nodes {
+		switch node.Type {
+		case graphNodeTypeComponent:
+			if _, ok := stackComponents[node.Stack]; !ok {
+				stackNames = append(stackNames, node.Stack)
+			}
+			stackComponents[node.Stack] = append(stackComponents[node.Stack], node)
+		case graphNodeTypeStack:
+			sb.WriteString(fmt.Sprintf("  %s [label=%s, shape=folder];\n", strconv.Quote(node.Id), strconv.Quote(node.Label)))
+		case graphNodeTypeImport:
+			sb.WriteString(fmt.Sprintf("  %s [label=%s, shape=note];\n", strconv.Quote(node.Id), strconv.Quote(node.Label)))
+		}
+	}
+
+	sort.Strings(stackNames)
+
+	for _, stackName := range stackNames {
+		sb.WriteString(fmt.Sprintf("  subgraph %s {\n", strconv.Quote("cluster_"+stackName)))
+		sb.WriteString(fmt.Sprintf("    label=%s;\n", strconv.Quote(stackName)))
+		for _, node := range stackComponents[stackName] {
+			style := ""
+			if node.Abstract {
+				style = ", style=dashed"
+			}
+			sb.WriteString(fmt.Sprintf("    %s [label=%s%s];\n", strconv.Quote(node.Id), strconv.Quote(node.Label), style))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	for _, edge := range graph.Edges {
+		attributes := []string{"label=" + strconv.Quote(edge.Type)}
+		switch edge.Type {
+		case graphEdgeTypeInherits:
+			attributes = append(attributes, "style=dashed")
+		case graphEdgeTypeImports:
+			attributes = append(attributes, "style=dotted")
+		}
+		if edge.Cycle {
+			attributes = append(attributes, "color=red")
+		}
+		sb.WriteString(fmt.Sprintf("  %s -> %s [%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strings.Join(attributes, ", ")))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// renderGraphMermaid renders the graph as a Mermaid flowchart. The components are grouped by stacks.
+// Mermaid node IDs can't contain special characters, so the nodes get generated IDs (`n1`, `n2`, etc.)
+func renderGraphMermaid(graph cfg.Graph) string {
+	var sb strings.Builder
+
+	sb.WriteString("flowchart LR\n")
+
+	nodeIds := map[string]string{}
+	stackComponents := map[string][]cfg.GraphNode{}
+	var stackNames []string
+
+	label := func(s string) string {
+		return strconv.Quote(strings.ReplaceAll(s, `"`, "#quot;"))
+	}
+
+	for i, node := range graph.Nodes {
+		nodeIds[node.Id] = fmt.Sprintf("n%d", i+1)
+
+		switch node.Type {
+		case graphNodeTypeComponent:
+			if _, ok := stackComponents[node.Stack]; !ok {
+				stackNames = append(stackNames, node.Stack)
+			}
+			stackComponents[node.Stack] = append(stackComponents[node.Stack], node)
+		case graphNodeTypeStack:
+			sb.WriteString(fmt.Sprintf("  %s[(%s)]\n", nodeIds[node.Id], label(node.Label)))
+		case graphNodeTypeImport:
+			sb.WriteString(fmt.Sprintf("  %s>%s]\n", nodeIds[node.Id], label(node.Label)))
+		}
+	}
+
+	sort.Strings(stackNames)
+
+	for i, stackName := range stackNames {
+		sb.WriteString(fmt.Sprintf("  subgraph s%d [%s]\n", i+1, label(stackName)))
+		for _, node := range stackComponents[stackName] {
+			sb.WriteString(fmt.Sprintf("    %s[%s]\n", nodeIds[node.Id], label(node.Label)))
+		}
+		sb.WriteString("  end\n")
+	}
+
+	var cycleEdges []string
+
+	for i, edge := range graph.Edges {
+		arrow := "-->"
+		switch edge.Type {
+		case graphEdgeTypeInherits:
+			arrow = "-.->"
+		case graphEdgeTypeImports:
+			arrow = "-.->"
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s|%s| %s\n", nodeIds[edge.From], arrow, edge.Type, nodeIds[edge.To]))
+		if edge.Cycle {
+			cycleEdges = append(cycleEdges, strconv.Itoa(i))
+		}
+	}
+
+	if len(cycleEdges) > 0 {
+		sb.WriteString(fmt.Sprintf("  linkStyle %s stroke:red\n", strings.Join(cycleEdges, ",")))
+	}
+
+	return sb.String()
+}
@@ -104,6 +104,20 @@ func ExecuteDescribeStacks(
 		return nil, err
 	}
 
+	return processStacksMap(cliConfig, stacksMap, filterByStack, components, componentTypes, sections)
+}
+
+// processStacksMap takes the map of the processed stack config files (keyed by the stack config file names)
+// and returns the final map of stacks and components
+func processStacksMap(
+	cliConfig cfg.CliConfiguration,
+	stacksMap map[string]any,
+	filterByStack string,
+	components []string,
+	componentTypes []string,
+	sections []string,
+) (map[string]any, error) {
+
 	finalStacksMap := make(map[string]any)
 	var varsSection map[any]any
 	var stackName string
@@ -13,8 +13,12 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 )
 
+// commandWaitDelay is the time to wait for the output of the command to be closed after the command is killed
+const commandWaitDelay = 5 * time.Second
+
 // ExecuteShellCommand prints and executes the provided command with args and flags
 func ExecuteShellCommand(
 	command string,
@@ -72,7 +76,7 @@ func ExecuteShell(command string, name string, dir string, env []string, dryRun
 		return nil
 	}
 
-	return shellRunner(command, name, dir, env, os.Stdout)
+	return shellRunner(context.TODO(), command, name, dir, env, os.Stdout, os.Stderr)
 }
 
 // ExecuteShellAndReturnOutput runs a shell script and capture its standard output
@@ -88,7 +92,7 @@ func ExecuteShellAndReturnOutput(command string, name string, dir string, env []
 		return "", nil
 	}
 
-	err := shellRunner(command, name, dir, env, &b)
+	err := shellRunner(context.TODO(), command, name, dir, env, &b, os.Stderr)
 	if err != nil {
 		return "", err
 	}
@@ -96,8 +100,8 @@ func ExecuteShellAndReturnOutput(command string, name string, dir string, env []
 	return b.String(), nil
 }
 
-// shellRunner uses mvdan.cc/sh/v3's parser and interpreter to run a shell script and divert its stdout
-func shellRunner(command string, name string, dir string, env []string, out io.Writer) error {
+// shellRunner uses mvdan.cc/sh/v3's parser and interpreter to run a shell script and divert its stdout and stderr
+func shellRunner(ctx context.Context, command string, name string, dir string, env []string, out io.Writer, errOut io.Writer) error {
 	parser, err := syntax.NewParser().Parse(strings.NewReader(command), name)
 	if err != nil {
 		return err
@@ -108,13 +112,57 @@ func shellRunner(command string, name string, dir string, env []string, out io.W
 	runner, err := interp.New(
 		interp.Dir(dir),
 		interp.Env(listEnviron),
-		interp.StdIO(os.Stdin, out, os.Stderr),
+		interp.StdIO(os.Stdin, out, errOut),
 	)
 	if err != nil {
 		return err
 	}
 
-	return runner.Run(context.TODO(), parser)
+	return runner.Run(ctx, parser)
+}
+
+// executeShellWithOutput runs a shell script and writes its stdout and stderr to the provided writers.
+// The script is stopped when the context is canceled
+func executeShellWithOutput(ctx context.Context, command string, name string, dir string, env []string, dryRun bool, out io.Writer, errOut io.Writer) error {
+	if dryRun {
+		return nil
+	}
+
+	return shellRunner(ctx, command, name, dir, env, out, errOut)
+}
+
+// executeShellCommandWithOutput executes the provided command with args and flags and writes its stdout and stderr to the provided writers.
+// The command is killed when the context is canceled
+func executeShellCommandWithOutput(
+	ctx context.Context,
+	command string,
+	args []string,
+	dir string,
+	env []string,
+	dryRun bool,
+	out io.Writer,
+	errOut io.Writer,
+) error {
+	if dryRun {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = out
+	cmd.Stderr = errOut
+
+	// Only the commands that can be canceled are started in their own process group.
+	// A process group in the background can't read from the terminal, so the commands without a timeout keep the interactive prompts working
+	if ctx.Done() != nil {
+		setCommandProcessGroup(cmd)
+		// Don't wait for the processes that keep the output pipes open after the command is killed
+		cmd.WaitDelay = commandWaitDelay
+	}
+
+	return cmd.Run()
 }
 
 // ExecuteShellCommandAndReturnOutput prints and executes the provided command with args and flags and returns the command output
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package exec
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setCommandProcessGroup starts the command in its own process group, and kills the whole group when the command's context is canceled.
+// This stops the processes started by the command (e.g. the Terraform providers), which would otherwise keep running after the command is killed
+func setCommandProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		// A negative PID sends the signal to all the processes in the process group
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
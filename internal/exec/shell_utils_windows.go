@@ -0,0 +1,11 @@
+//go:build windows
+
+package exec
+
+import (
+	"os/exec"
+)
+
+// setCommandProcessGroup is a no-op on Windows, the command is killed when the command's context is canceled
+func setCommandProcessGroup(cmd *exec.Cmd) {
+}
@@ -0,0 +1,389 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+const (
+	terraformRunAllStatusPending   = "pending"
+	terraformRunAllStatusRunning   = "running"
+	terraformRunAllStatusSucceeded = "succeeded"
+	terraformRunAllStatusFailed    = "failed"
+	// The component was not executed because one of its dependencies failed
+	terraformRunAllStatusSkipped = "skipped"
+)
+
+// TerraformRunAllFilter selects the components for `terraform plan-all` and `terraform apply-all` commands.
+// Empty filters match all the components
+type TerraformRunAllFilter struct {
+	Stacks     []string
+	Components []string
+	// The output of `atmos describe affected` command
+	Affected []cfg.Affected
+}
+
+// TerraformRunAllResult is the result of executing a terraform command for a component in a stack
+type TerraformRunAllResult struct {
+	Component string
+	Stack     string
+	Status    string
+	Duration  time.Duration
+	Err       error
+}
+
+// ExecuteTerraformPlanAllCmd executes `terraform plan-all` command
+func ExecuteTerraformPlanAllCmd(cmd *cobra.Command, args []string) error {
+	return executeTerraformRunAllCmd(cmd, args, "plan")
+}
+
+// ExecuteTerraformApplyAllCmd executes `terraform apply-all` command
+func ExecuteTerraformApplyAllCmd(cmd *cobra.Command, args []string) error {
+	return executeTerraformRunAllCmd(cmd, args, "apply")
+}
+
+func executeTerraformRunAllCmd(cmd *cobra.Command, args []string, subCommand string) error {
+	info, err := processCommandLineArgs("", cmd, nil)
+	if err != nil {
+		return err
+	}
+
+	cliConfig, err := cfg.InitCliConfig(info, true)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+
+	var filter TerraformRunAllFilter
+
+	stacksCsv, err := flags.GetString("stacks")
+	if err != nil {
+		return err
+	}
+	if stacksCsv != "" {
+		filter.Stacks = strings.Split(stacksCsv, ",")
+	}
+
+	if info.Stack != "" {
+		filter.Stacks = append(filter.Stacks, info.Stack)
+	}
+
+	componentsCsv, err := flags.GetString("components")
+	if err != nil {
+		return err
+	}
+	if componentsCsv != "" {
+		filter.Components = strings.Split(componentsCsv, ",")
+	}
+
+	affectedFile, err := flags.GetString("affected-file")
+	if err != nil {
+		return err
+	}
+	if affectedFile != "" {
+		filter.Affected, err = readAffectedFile(affectedFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	maxParallel, err := flags.GetInt("max-parallel")
+	if err != nil {
+		return err
+	}
+
+	dryRun, err := flags.GetBool("dry-run")
+	if err != nil {
+		return err
+	}
+
+	// The arguments after `--` are passed to the terraform command for each component
+	results, err := ExecuteTerraformRunAll(cliConfig, subCommand, filter, maxParallel, dryRun, args)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		u.PrintInfo("No components found")
+		return nil
+	}
+
+	if err = printTerraformRunAllSummary(results); err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, result := range results {
+		if result.Status == terraformRunAllStatusFailed {
+			failed = append(failed, fmt.Sprintf("'%s' in the stack '%s': %v", result.Component, result.Stack, result.Err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("'terraform %s' failed for %d of %d components:\n%s", subCommand, len(failed), len(results), strings.Join(failed, "\n"))
+	}
+
+	return nil
+}
+
+// readAffectedFile reads the output of `atmos describe affected` command (in JSON or YAML format) from the file, or from `stdin` if the file is `-`
+func readAffectedFile(file string) ([]cfg.Affected, error) {
+	var content []byte
+	var err error
+
+	if file == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is a subset of YAML, so the YAML parser reads both formats
+	var affected []cfg.Affected
+	if err = yaml.Unmarshal(content, &affected); err != nil {
+		return nil, fmt.Errorf("invalid affected file '%s': %w", file, err)
+	}
+
+	return affected, nil
+}
+
+// ExecuteTerraformRunAll executes the terraform command (`plan` or `apply`) for all the terraform components selected by the filter.
+// The components are executed in the order of the dependencies defined in the `settings.depends_on` sections:
+// a component is executed after all the selected components it depends on (directly, or through the components that are not selected) have succeeded.
+// Up to `maxParallel` components are executed at the same time, but the components with the same working dir are never executed at the same time.
+// If a component fails, the components that depend on it are skipped, and the other components continue to be executed.
+// The results are returned in the order in which the components were started
+func ExecuteTerraformRunAll(
+	cliConfig cfg.CliConfiguration,
+	subCommand string,
+	filter TerraformRunAllFilter,
+	maxParallel int,
+	dryRun bool,
+	additionalArgsAndFlags []string,
+) ([]TerraformRunAllResult, error) {
+
+	if subCommand != "plan" && subCommand != "apply" {
+		return nil, fmt.Errorf("invalid terraform command '%s'. Supported commands are 'plan' and 'apply'", subCommand)
+	}
+
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	stacks, err := ExecuteDescribeStacks(cliConfig, "", nil, []string{"terraform"}, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := buildDependencyGraph(cliConfig, stacks)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	selected := map[string]bool{}
+
+	for _, id := range graph.ids {
+		node := graph.nodes[id]
+		if node.ComponentType == "terraform" && terraformRunAllFilterMatches(filter, node) {
+			ids = append(ids, id)
+			selected[id] = true
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if cycle := findDependencyGraphCycle(graph, ids); cycle != nil {
+		return nil, fmt.Errorf("the components have a dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+
+	dependencies := map[string][]string{}
+	for _, id := range ids {
+		dependencies[id] = selectedDependencies(graph, id, selected)
+	}
+
+	args := []string{subCommand}
+	if subCommand == "apply" {
+		args = append(args, autoApproveFlag)
+	}
+	args = append(args, additionalArgsAndFlags...)
+
+	u.PrintInfo(fmt.Sprintf("\nExecuting 'terraform %s' for %d components\n", subCommand, len(ids)))
+
+	statuses := map[string]string{}
+	for _, id := range ids {
+		statuses[id] = terraformRunAllStatusPending
+	}
+
+	var results []TerraformRunAllResult
+	resultIndexes := map[string]int{}
+
+	// Working dirs of the running components
+	busyWorkingDirs := map[string]bool{}
+
+	type completion struct {
+		id       string
+		duration time.Duration
+		err      error
+	}
+
+	done := make(chan completion)
+	running := 0
+
+	for {
+		progress := true
+		for progress {
+			progress = false
+
+			for _, id := range ids {
+				if running >= maxParallel {
+					break
+				}
+
+				if statuses[id] != terraformRunAllStatusPending {
+					continue
+				}
+
+				node := graph.nodes[id]
+				ready := true
+
+				for _, dependencyId := range dependencies[id] {
+					switch statuses[dependencyId] {
+					case terraformRunAllStatusSucceeded:
+						continue
+					case terraformRunAllStatusFailed, terraformRunAllStatusSkipped:
+						// Skip the component since one of its dependencies did not succeed.
+						// Check the pending components again since the components that depend on this one must be skipped too
+						statuses[id] = terraformRunAllStatusSkipped
+						resultIndexes[id] = len(results)
+						results = append(results, TerraformRunAllResult{
+							Component: node.Component,
+							Stack:     node.Stack,
+							Status:    terraformRunAllStatusSkipped,
+							Err:       fmt.Errorf("the dependency '%s' did not succeed", dependencyId),
+						})
+						progress = true
+					}
+					ready = false
+					break
+				}
+
+				if !ready || busyWorkingDirs[node.ComponentPath] {
+					continue
+				}
+
+				statuses[id] = terraformRunAllStatusRunning
+				busyWorkingDirs[node.ComponentPath] = true
+				resultIndexes[id] = len(results)
+				results = append(results, TerraformRunAllResult{Component: node.Component, Stack: node.Stack, Status: terraformRunAllStatusRunning})
+				running++
+
+				go func(id string, node cfg.DependencyGraphNode) {
+					start := time.Now()
+					err := executeTerraformRunAllComponent(node, args, dryRun)
+					done <- completion{id: id, duration: time.Since(start), err: err}
+				}(id, *node)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+
+		c := <-done
+		running--
+
+		busyWorkingDirs[graph.nodes[c.id].ComponentPath] = false
+
+		result := &results[resultIndexes[c.id]]
+		result.Duration = c.duration
+		result.Err = c.err
+		result.Status = terraformRunAllStatusSucceeded
+		if c.err != nil {
+			result.Status = terraformRunAllStatusFailed
+		}
+		statuses[c.id] = result.Status
+	}
+
+	return results, nil
+}
+
+// terraformRunAllFilterMatches checks if the component in the stack is selected by the filter
+func terraformRunAllFilterMatches(filter TerraformRunAllFilter, node *cfg.DependencyGraphNode) bool {
+	if len(filter.Stacks) > 0 && !u.SliceContainsString(filter.Stacks, node.Stack) {
+		return false
+	}
+
+	if len(filter.Components) > 0 && !u.SliceContainsString(filter.Components, node.Component) {
+		return false
+	}
+
+	if filter.Affected != nil {
+		for _, affected := range filter.Affected {
+			if affected.ComponentType == node.ComponentType && affected.Component == node.Component && affected.Stack == node.Stack {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// executeTerraformRunAllComponent executes `atmos terraform <command> <component> -s <stack>` and prefixes each line of the output with the component and stack
+func executeTerraformRunAllComponent(node cfg.DependencyGraphNode, args []string, dryRun bool) error {
+	prefix := fmt.Sprintf("[%s/%s] ", node.Stack, node.Component)
+	out := newWorkflowStepOutputWriter(prefix, os.Stdout)
+	errOut := newWorkflowStepOutputWriter(prefix, os.Stderr)
+
+	defer out.Flush()
+	defer errOut.Flush()
+
+	commandArgs := []string{"terraform", args[0], node.Component, "-s", node.Stack}
+	commandArgs = append(commandArgs, args[1:]...)
+
+	fmt.Fprintf(out, "Executing command: atmos %s\n", strings.Join(commandArgs, " "))
+
+	return executeShellCommandWithOutput(context.Background(), "atmos", commandArgs, ".", []string{}, dryRun, out, errOut)
+}
+
+// printTerraformRunAllSummary prints the table with the results of executing the terraform command for the components
+func printTerraformRunAllSummary(results []TerraformRunAllResult) error {
+	fmt.Println()
+	u.PrintInfo("Summary:")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "COMPONENT\tSTACK\tSTATUS\tDURATION")
+
+	for _, result := range results {
+		duration := "-"
+		if result.Status != terraformRunAllStatusSkipped {
+			duration = result.Duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Component, result.Stack, result.Status, duration)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	return nil
+}
@@ -198,6 +198,7 @@ func FindStacksMap(cliConfig cfg.CliConfiguration, ignoreMissingFiles bool) (
 ) {
 	// Process stack config file(s)
 	_, stacksMap, rawStackConfigs, err := s.ProcessYAMLConfigFiles(
+		cliConfig,
 		cliConfig.StacksBaseAbsolutePath,
 		cliConfig.TerraformDirAbsolutePath,
 		cliConfig.HelmfileDirAbsolutePath,
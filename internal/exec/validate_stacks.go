@@ -5,6 +5,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"path"
+	"path/filepath"
 	"strings"
 
 	cfg "github.com/cloudposse/atmos/pkg/config"
@@ -40,14 +41,22 @@ func ExecuteValidateStacksCmd(cmd *cobra.Command, args []string) error {
 
 	var errorMessages []string
 
+	// The processed top-level stack config files (keyed by the stack config file names) to check the dependencies of the components
+	stacksMap := map[string]any{}
+	stacksValid := true
+
 	for _, filePath := range stackConfigFilesAbsolutePaths {
-		stackConfig, importsConfig, _, err := s.ProcessYAMLConfigFile(cliConfig.StacksBaseAbsolutePath, filePath, map[string]map[any]any{}, nil, false)
+		isStack := u.SliceContainsString(cliConfig.StackConfigFilesAbsolutePaths, filePath)
+
+		stackConfig, importsConfig, _, err := s.ProcessYAMLConfigFile(cliConfig, cliConfig.StacksBaseAbsolutePath, filePath, map[string]map[any]any{}, nil, false)
 		if err != nil {
 			errorMessages = append(errorMessages, err.Error())
+			stacksValid = stacksValid && !isStack
 		}
 
 		componentStackMap := map[string]map[string][]string{}
-		_, err = s.ProcessStackConfig(
+		finalConfig, err := s.ProcessStackConfig(
+			cliConfig,
 			cliConfig.StacksBaseAbsolutePath,
 			cliConfig.TerraformDirAbsolutePath,
 			cliConfig.HelmfileDirAbsolutePath,
@@ -59,9 +68,32 @@ func ExecuteValidateStacksCmd(cmd *cobra.Command, args []string) error {
 			componentStackMap,
 			importsConfig,
 			false)
+		if err != nil {
+			errorMessages = append(errorMessages, err.Error())
+			stacksValid = stacksValid && !isStack
+			continue
+		}
+
+		if isStack {
+			stackFileName := strings.TrimSuffix(
+				strings.TrimSuffix(
+					u.TrimBasePathFromPath(cliConfig.StacksBaseAbsolutePath+"/", filePath),
+					cfg.DefaultStackConfigFileExtension),
+				".yml",
+			)
+			stacksMap[stackFileName] = finalConfig
+		}
+	}
+
+	// Check the `settings.depends_on` sections of the components in all the stacks.
+	// If any top-level stack config file is invalid, the dependencies can't be resolved correctly, and the errors are already reported.
+	// The other invalid files (e.g. the catalog files that are not imported by any stack) don't affect the stacks
+	if stacksValid {
+		dependencyErrorMessages, err := validateStacksDependencies(cliConfig, stacksMap)
 		if err != nil {
 			errorMessages = append(errorMessages, err.Error())
 		}
+		errorMessages = append(errorMessages, dependencyErrorMessages...)
 	}
 
 	if len(errorMessages) > 0 {
@@ -70,3 +102,95 @@ func ExecuteValidateStacksCmd(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// validateStacksDependencies resolves the items of the `settings.depends_on` sections of the components in all the stacks,
+// and returns the error messages for the items that don't point to any component in any stack, and for the dependency cycles.
+// It takes the already processed stack config files to not process them again
+func validateStacksDependencies(cliConfig cfg.CliConfiguration, stacksMap map[string]any) ([]string, error) {
+	stacks, err := processStacksMap(cliConfig, stacksMap, "", nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := buildDependencyGraph(cliConfig, stacks)
+	if err != nil {
+		return nil, err
+	}
+
+	var errorMessages []string
+
+	describeNode := func(id string) string {
+		node := graph.nodes[id]
+		return fmt.Sprintf("the %s component '%s' in the stack '%s' (stack config file '%s')",
+			node.ComponentType,
+			node.Component,
+			node.Stack,
+			findComponentStackConfigFile(cliConfig, stacks, node),
+		)
+	}
+
+	for _, unresolved := range graph.unresolved {
+		var context []string
+		for _, item := range [][]string{
+			{"namespace", unresolved.dependency.Namespace},
+			{"tenant", unresolved.dependency.Tenant},
+			{"environment", unresolved.dependency.Environment},
+			{"stage", unresolved.dependency.Stage},
+		} {
+			if item[1] != "" {
+				context = append(context, fmt.Sprintf("%s: %s", item[0], item[1]))
+			}
+		}
+
+		errorMessages = append(errorMessages, fmt.Sprintf("%s depends on the component '%s' (%s) in 'settings.depends_on', "+
+			"but the component is not defined in any stack with this context, or it is abstract",
+			describeNode(unresolved.id),
+			unresolved.dependency.Component,
+			strings.Join(context, ", "),
+		))
+	}
+
+	for _, cycle := range findDependencyGraphCycles(graph) {
+		var components []string
+		for _, id := range cycle {
+			components = append(components, describeNode(id))
+		}
+		errorMessages = append(errorMessages, fmt.Sprintf("the components depend on each other in 'settings.depends_on' (dependency cycle):\n%s",
+			strings.Join(components, "\n")))
+	}
+
+	return errorMessages, nil
+}
+
+// findComponentStackConfigFile returns the top-level stack config file that defines the component in the stack
+// (the stack config file from the component `deps`)
+func findComponentStackConfigFile(cliConfig cfg.CliConfiguration, stacks map[string]any, node *cfg.DependencyGraphNode) string {
+	stackSection, ok := stacks[node.Stack].(map[string]any)
+	if !ok {
+		return ""
+	}
+	componentsSection, ok := stackSection["components"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	componentTypeSection, ok := componentsSection[node.ComponentType].(map[string]any)
+	if !ok {
+		return ""
+	}
+	componentSection, ok := componentTypeSection[node.Component].(map[string]any)
+	if !ok {
+		return ""
+	}
+	deps, ok := componentSection["deps"].([]string)
+	if !ok {
+		return ""
+	}
+
+	for _, stackConfigFile := range cliConfig.StackConfigFilesRelativePaths {
+		if u.SliceContainsString(deps, strings.TrimSuffix(stackConfigFile, filepath.Ext(stackConfigFile))) {
+			return stackConfigFile
+		}
+	}
+
+	return ""
+}
@@ -9,6 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	cueErrors "cuelang.org/go/cue/errors"
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/open-policy-agent/opa/sdk"
 	opaTestServer "github.com/open-policy-agent/opa/sdk/test"
@@ -143,5 +146,77 @@ func ValidateWithOpa(data any, schemaName string, schemaText string) (bool, erro
 // ValidateWithCue validates the data structure using the provided CUE document
 // https://cuelang.org/docs/integrations/go/#processing-cue-in-go
 func ValidateWithCue(data any, schemaName string, schemaText string) (bool, error) {
-	return false, errors.New("validation using CUE is not implemented yet")
+	// The CUE encoder does not support map[any]any data types (which can be part of 'data' input)
+	// To fix the issue, convert the data to JSON and back to Go map
+	dataJson, err := u.ConvertToJSONFast(data)
+	if err != nil {
+		return false, err
+	}
+
+	dataFromJson, err := u.ConvertFromJSON(dataJson)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := cuecontext.New()
+
+	schema := ctx.CompileString(schemaText, cue.Filename(schemaName))
+	if schema.Err() != nil {
+		return false, schema.Err()
+	}
+
+	dataValue := ctx.Encode(dataFromJson)
+	if dataValue.Err() != nil {
+		return false, dataValue.Err()
+	}
+
+	// Unify the schema with the data and check that the result is valid and concrete
+	// (all the fields required by the schema are provided in the data)
+	if err = schema.Unify(dataValue).Validate(cue.Concrete(true), cue.All()); err != nil {
+		b, err2 := json.MarshalIndent(cueBasicOutput(err), "", "  ")
+		if err2 != nil {
+			return false, err2
+		}
+		return false, errors.New(string(b))
+	}
+
+	return true, nil
+}
+
+// cueValidationError represents a CUE unification error in the same shape as the JSON Schema `BasicOutput` errors
+type cueValidationError struct {
+	KeywordLocation  string `json:"keywordLocation,omitempty"`
+	InstanceLocation string `json:"instanceLocation"`
+	Error            string `json:"error"`
+}
+
+// cueValidationOutput represents the CUE validation result in the same shape as the JSON Schema `BasicOutput`
+type cueValidationOutput struct {
+	Valid  bool                 `json:"valid"`
+	Errors []cueValidationError `json:"errors"`
+}
+
+// cueBasicOutput converts CUE errors into the flat list of errors with their paths
+func cueBasicOutput(err error) cueValidationOutput {
+	output := cueValidationOutput{
+		Valid:  false,
+		Errors: []cueValidationError{},
+	}
+
+	for _, e := range cueErrors.Errors(err) {
+		format, args := e.Msg()
+
+		validationError := cueValidationError{
+			InstanceLocation: "/" + strings.Join(e.Path(), "/"),
+			Error:            fmt.Sprintf(format, args...),
+		}
+
+		if pos := e.Position(); pos.IsValid() {
+			validationError.KeywordLocation = pos.String()
+		}
+
+		output.Errors = append(output.Errors, validationError)
+	}
+
+	return output
 }
@@ -0,0 +1,353 @@
+package exec
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-getter"
+	cp "github.com/otiai10/copy"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+var (
+	// Mutex to serialize updates of the vendorCacheLocks map
+	vendorCacheLocksLock = &sync.Mutex{}
+
+	// Mutexes (one per cache key) to prevent downloading the same source concurrently
+	vendorCacheLocks = map[string]*sync.Mutex{}
+
+	// Full Git commit SHA
+	vendorCommitShaRegexp = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+	// Version tag (e.g. `1.2.3`, `v1.2.3`, `1.2.3-rc1`)
+	vendorVersionTagRegexp = regexp.MustCompile(`^v?[0-9]+(\.[0-9]+)*([-+][0-9A-Za-z.-]+)?$`)
+)
+
+// getVendorCacheLock returns the mutex for the provided cache key
+func getVendorCacheLock(key string) *sync.Mutex {
+	vendorCacheLocksLock.Lock()
+	defer vendorCacheLocksLock.Unlock()
+
+	if _, ok := vendorCacheLocks[key]; !ok {
+		vendorCacheLocks[key] = &sync.Mutex{}
+	}
+
+	return vendorCacheLocks[key]
+}
+
+// getVendorCacheDir returns the folder of the vendoring cache.
+// If `vendor.cache.base_path` is not configured, the user cache dir is used (e.g. `~/.cache/atmos/vendor` on Linux)
+func getVendorCacheDir(cliConfig cfg.CliConfiguration) (string, error) {
+	cacheDir := cliConfig.Vendor.Cache.BasePath
+
+	if cacheDir == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+		cacheDir = path.Join(userCacheDir, "atmos", "vendor")
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+
+	return cacheDir, nil
+}
+
+// getVendorCacheKey returns the content-addressed cache key for the resolved URI and version
+func getVendorCacheKey(uri string, version string) string {
+	hash := sha256.Sum256([]byte(uri + "\x00" + version))
+	return hex.EncodeToString(hash[:])
+}
+
+// isVendorCacheable checks if the downloaded source can be cached.
+// The cache entries are never invalidated, so only the sources pinned to immutable revisions are cached.
+// Local files and folders are not cached since they can change at any time
+func isVendorCacheable(cliConfig cfg.CliConfiguration, uri string) bool {
+	if !cliConfig.Vendor.Cache.Enabled {
+		return false
+	}
+	if strings.HasPrefix(uri, "file://") || strings.HasPrefix(uri, "file::") {
+		return false
+	}
+	if u.FileOrDirExists(uri) {
+		return false
+	}
+	return isVendorImmutableSource(uri)
+}
+
+// isVendorImmutableSource checks if the source URI points to an immutable revision.
+// A Git source must be pinned with `ref` to a commit SHA or a version tag (e.g. `?ref=1.2.3` or `?ref=v1.2.3`),
+// since a branch (or the default branch if `ref` is not specified) can change at any time.
+// Other sources (e.g. archives, S3 and GCS objects) are considered immutable unless they specify a `ref` that is not immutable
+func isVendorImmutableSource(uri string) bool {
+	ref := ""
+	if i := strings.Index(uri, "?"); i >= 0 {
+		if query, err := url.ParseQuery(uri[i+1:]); err == nil {
+			ref = query.Get("ref")
+		}
+	}
+
+	if ref != "" {
+		return vendorCommitShaRegexp.MatchString(ref) || vendorVersionTagRegexp.MatchString(ref)
+	}
+
+	return !isVendorGitSource(uri)
+}
+
+// isVendorGitSource checks if the source URI is a Git repository
+func isVendorGitSource(uri string) bool {
+	if strings.HasPrefix(uri, "git::") || strings.HasPrefix(uri, "git@") {
+		return true
+	}
+
+	source, _ := getter.SourceDirSubdir(uri)
+	source = strings.SplitN(source, "?", 2)[0]
+	if strings.HasSuffix(source, ".git") {
+		return true
+	}
+
+	for _, host := range []string{"github.com/", "gitlab.com/", "bitbucket.org/"} {
+		if strings.HasPrefix(source, host) || strings.Contains(source, "://"+host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// downloadVendorSourceDir downloads the source folder (Git repo, archive, etc.) and returns the path to the folder with the downloaded files.
+// If the cache is enabled, the source repository (without the go-getter subdirectory) is downloaded once into the cache
+// and reused for all components and sources that use the same URI and version.
+// If the cache is disabled, the source is downloaded into the provided temp folder
+func downloadVendorSourceDir(cliConfig cfg.CliConfiguration, uri string, version string, tempDir string) (string, error) {
+	if !isVendorCacheable(cliConfig, uri) {
+		sourceDir := path.Join(tempDir, "source")
+
+		client := &getter.Client{
+			Ctx: context.Background(),
+			// Define the destination to where the files will be stored. This will create the directory if it doesn't exist
+			Dst: sourceDir,
+			// Source
+			Src:  uri,
+			Mode: getter.ClientModeDir,
+		}
+
+		if err := client.Get(); err != nil {
+			return "", err
+		}
+
+		// go-getter creates a symlink to a local source folder. Resolve it to copy the actual files
+		if resolvedSourceDir, err := filepath.EvalSymlinks(sourceDir); err == nil {
+			sourceDir = resolvedSourceDir
+		}
+
+		return sourceDir, nil
+	}
+
+	// Split the URI into the source (e.g. Git repo) and the subdirectory in it.
+	// This allows cloning the Git repo once for all the components from different subdirectories of the repo.
+	// https://github.com/hashicorp/go-getter#subdirectories
+	sourceUri, subDir := getter.SourceDirSubdir(uri)
+
+	cachePath, err := downloadToVendorCache(cliConfig, sourceUri, version, getter.ClientModeDir)
+	if err != nil {
+		return "", err
+	}
+
+	if subDir == "" {
+		return cachePath, nil
+	}
+
+	return getter.SubdirGlob(cachePath, subDir)
+}
+
+// downloadVendorFile downloads the file (e.g. a component mixin) and writes it to the destination file path
+func downloadVendorFile(cliConfig cfg.CliConfiguration, uri string, dst string) error {
+	if !isVendorCacheable(cliConfig, uri) {
+		client := &getter.Client{
+			Ctx:  context.Background(),
+			Dst:  dst,
+			Src:  uri,
+			Mode: getter.ClientModeFile,
+		}
+
+		return client.Get()
+	}
+
+	cachePath, err := downloadToVendorCache(cliConfig, uri, "", getter.ClientModeFile)
+	if err != nil {
+		return err
+	}
+
+	return cp.Copy(cachePath, dst)
+}
+
+// downloadToVendorCache downloads the source into the cache (if it's not already in the cache) and returns the path to it in the cache
+func downloadToVendorCache(cliConfig cfg.CliConfiguration, uri string, version string, mode getter.ClientMode) (string, error) {
+	cacheDir, err := getVendorCacheDir(cliConfig)
+	if err != nil {
+		return "", err
+	}
+
+	key := getVendorCacheKey(uri, version)
+	cachePath := path.Join(cacheDir, key)
+
+	lock := getVendorCacheLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if u.FileOrDirExists(cachePath) {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Using the cached source '%s' from '%s'", uri, cachePath))
+		return cachePath, nil
+	}
+
+	// Download into a temp folder in the cache dir and then rename it, so an interrupted download never leaves a partial entry in the cache
+	tempDir, err := os.MkdirTemp(cacheDir, key+"-")
+	if err != nil {
+		return "", err
+	}
+
+	defer removeTempDir(tempDir)
+
+	tempPath := path.Join(tempDir, "source")
+
+	client := &getter.Client{
+		Ctx:  context.Background(),
+		Dst:  tempPath,
+		Src:  uri,
+		Mode: mode,
+	}
+
+	if err = client.Get(); err != nil {
+		return "", err
+	}
+
+	if err = os.Rename(tempPath, cachePath); err != nil {
+		// Another `atmos` process could have written the same cache entry
+		if u.FileOrDirExists(cachePath) {
+			return cachePath, nil
+		}
+		return "", err
+	}
+
+	return cachePath, nil
+}
+
+// vendorPathIncluded checks if the file matches the 'included_paths' patterns (if any) and does not match any of the 'excluded_paths' patterns
+func vendorPathIncluded(source cfg.VendorComponentSource, filePath string) (bool, error) {
+	for _, excludePath := range source.ExcludedPaths {
+		excludeMatch, err := u.PathMatch(excludePath, filePath)
+		if err != nil {
+			return false, err
+		} else if excludeMatch {
+			return false, nil
+		}
+	}
+
+	if len(source.IncludedPaths) == 0 {
+		return true, nil
+	}
+
+	for _, includePath := range source.IncludedPaths {
+		includeMatch, err := u.PathMatch(includePath, filePath)
+		if err != nil {
+			return false, err
+		} else if includeMatch {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// listVendorFiles returns the sorted list of the files (relative to the folder) that match the 'included_paths' and 'excluded_paths' patterns
+func listVendorFiles(dir string, source cfg.VendorComponentSource) ([]string, error) {
+	var files []string
+
+	if !u.FileOrDirExists(dir) {
+		return files, nil
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		included, err := vendorPathIncluded(source, p)
+		if err != nil {
+			return err
+		}
+
+		if included {
+			relPath, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(relPath))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// checksumVendorFiles calculates the SHA256 checksum of the files (their relative paths and contents) in the folder.
+// If any of the files does not exist, an empty checksum is returned
+func checksumVendorFiles(dir string, files []string) (string, error) {
+	hash := sha256.New()
+
+	for _, f := range files {
+		content, err := os.ReadFile(path.Join(dir, f))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", nil
+			}
+			return "", err
+		}
+
+		hash.Write([]byte(f))
+		hash.Write([]byte{0})
+		hash.Write(content)
+		hash.Write([]byte{0})
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checksumVendorFolder calculates the SHA256 checksum of the files in the folder that match the 'included_paths' and 'excluded_paths' patterns
+func checksumVendorFolder(dir string, source cfg.VendorComponentSource) (string, error) {
+	files, err := listVendorFiles(dir, source)
+	if err != nil {
+		return "", err
+	}
+
+	return checksumVendorFiles(dir, files)
+}
@@ -0,0 +1,395 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cp "github.com/otiai10/copy"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v2"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// ReadAndProcessAtmosVendorConfigFile reads and processes the repository-level `vendor.yaml` vendor config file
+func ReadAndProcessAtmosVendorConfigFile(vendorConfigFile string) (cfg.AtmosVendorConfig, error) {
+	var vendorConfig cfg.AtmosVendorConfig
+
+	if !u.FileExists(vendorConfigFile) {
+		return vendorConfig, fmt.Errorf("vendor config file '%s' does not exist", vendorConfigFile)
+	}
+
+	vendorConfigFileContent, err := os.ReadFile(vendorConfigFile)
+	if err != nil {
+		return vendorConfig, err
+	}
+
+	if err = yaml.Unmarshal(vendorConfigFileContent, &vendorConfig); err != nil {
+		return vendorConfig, err
+	}
+
+	if vendorConfig.Kind != cfg.AtmosVendorConfigKind {
+		return vendorConfig, fmt.Errorf("invalid 'kind: %s' in the vendor config file '%s'. Supported kinds: '%s'",
+			vendorConfig.Kind,
+			vendorConfigFile,
+			cfg.AtmosVendorConfigKind,
+		)
+	}
+
+	for i, source := range vendorConfig.Spec.Sources {
+		if source.Source == "" {
+			return vendorConfig, fmt.Errorf("'source' must be specified in 'spec.sources[%d]' in the vendor config file '%s'", i, vendorConfigFile)
+		}
+		if len(source.Targets) == 0 {
+			return vendorConfig, fmt.Errorf("'targets' must be specified in 'spec.sources[%d]' in the vendor config file '%s'", i, vendorConfigFile)
+		}
+	}
+
+	return vendorConfig, nil
+}
+
+// ReadAtmosVendorLockFile reads the `vendor.lock.yaml` lock file. If the file does not exist, an empty lock is returned
+func ReadAtmosVendorLockFile(vendorLockFile string) (cfg.AtmosVendorLock, error) {
+	vendorLock := cfg.AtmosVendorLock{
+		ApiVersion: cfg.AtmosVendorConfigApiVersion,
+		Kind:       cfg.AtmosVendorLockKind,
+	}
+
+	if !u.FileExists(vendorLockFile) {
+		return vendorLock, nil
+	}
+
+	vendorLockFileContent, err := os.ReadFile(vendorLockFile)
+	if err != nil {
+		return vendorLock, err
+	}
+
+	if err = yaml.Unmarshal(vendorLockFileContent, &vendorLock); err != nil {
+		return vendorLock, err
+	}
+
+	return vendorLock, nil
+}
+
+// ExecuteAtmosVendorInternal executes the vendor command for all the sources defined in the repository-level `vendor.yaml` file.
+// If `tags` are provided, only the sources that have at least one of the tags are processed.
+// The sources are processed concurrently (up to `vendor.max_concurrency` sources at a time).
+// The `pull` command writes the resolved URIs and the content checksums of the pulled sources to the `vendor.lock.yaml` file
+func ExecuteAtmosVendorInternal(
+	cliConfig cfg.CliConfiguration,
+	vendorConfigFile string,
+	tags []string,
+	dryRun bool,
+	vendorCommand string,
+) error {
+
+	vendorConfig, err := ReadAndProcessAtmosVendorConfigFile(vendorConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Relative paths in the `source` and `targets` attributes are relative to the folder where the `vendor.yaml` file is located
+	vendorConfigPath := filepath.Dir(vendorConfigFile)
+	vendorLockFile := path.Join(vendorConfigPath, cfg.AtmosVendorLockFileName)
+
+	vendorLock, err := ReadAtmosVendorLockFile(vendorLockFile)
+	if err != nil {
+		return err
+	}
+
+	lockedSources := map[string]cfg.AtmosVendorLockSource{}
+	for _, lockedSource := range vendorLock.Spec.Sources {
+		lockedSources[atmosVendorSourceKey(lockedSource.Component, lockedSource.Source, lockedSource.Targets)] = lockedSource
+	}
+
+	// The processed targets and the keys of the sources in the lock file
+	targets := make([][]string, len(vendorConfig.Spec.Sources))
+	keys := make([]string, len(vendorConfig.Spec.Sources))
+	for i, source := range vendorConfig.Spec.Sources {
+		if targets[i], err = processAtmosVendorSourceTargets(i, source); err != nil {
+			return err
+		}
+		keys[i] = atmosVendorSourceKey(source.Component, source.Source, targets[i])
+	}
+
+	// The goroutines write the results to their own items (by the index of the source), so `lockedSources` is only accessed from this goroutine.
+	// All the sources are processed even if some of them fail, so the drift of all the sources is reported by the `diff` command
+	pulledSources := make([]*cfg.AtmosVendorLockSource, len(vendorConfig.Spec.Sources))
+	driftedSources := make([]bool, len(vendorConfig.Spec.Sources))
+	errs := make([]error, len(vendorConfig.Spec.Sources))
+	processedSources := 0
+
+	g := new(errgroup.Group)
+	g.SetLimit(vendorMaxConcurrency(cliConfig))
+
+	for i, source := range vendorConfig.Spec.Sources {
+		if len(tags) > 0 && !u.SliceContainsAnyString(source.Tags, tags) {
+			continue
+		}
+
+		processedSources++
+
+		i, source := i, source
+
+		var previousLockedSource *cfg.AtmosVendorLockSource
+		if lockedSource, ok := lockedSources[keys[i]]; ok {
+			previousLockedSource = &lockedSource
+		}
+
+		g.Go(func() error {
+			lockedSource, drifted, err := processAtmosVendorSource(cliConfig, i, source, vendorConfigPath, previousLockedSource, dryRun, vendorCommand)
+			if err != nil {
+				errs[i] = err
+				return nil
+			}
+
+			driftedSources[i] = drifted
+			pulledSources[i] = &lockedSource
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	var drifted []string
+	for i, source := range vendorConfig.Spec.Sources {
+		if driftedSources[i] {
+			drifted = append(drifted, atmosVendorSourceDescription(source, targets[i]))
+		}
+
+		if vendorCommand == "pull" && !dryRun && pulledSources[i] != nil {
+			lockedSources[keys[i]] = *pulledSources[i]
+		}
+	}
+
+	if processedSources == 0 {
+		return fmt.Errorf("no sources found in the vendor config file '%s' for the tags %v", vendorConfigFile, tags)
+	}
+
+	if len(drifted) > 0 {
+		sort.Strings(drifted)
+		errs = append(errs, &VendorDriftError{Drifts: drifted})
+	}
+
+	if err = joinVendorErrors(errs); err != nil {
+		return err
+	}
+
+	if vendorCommand != "pull" || dryRun {
+		return nil
+	}
+
+	// Write the lock file in the order of the sources in the `vendor.yaml` file.
+	// The sources that were removed from the `vendor.yaml` file are removed from the lock file
+	vendorLock.Spec.Sources = []cfg.AtmosVendorLockSource{}
+	for i := range vendorConfig.Spec.Sources {
+		if lockedSource, ok := lockedSources[keys[i]]; ok {
+			vendorLock.Spec.Sources = append(vendorLock.Spec.Sources, lockedSource)
+		}
+	}
+
+	fmt.Println()
+	u.PrintInfo(fmt.Sprintf("Writing the vendor lock file '%s'\n", vendorLockFile))
+
+	return u.WriteToFileAsYAML(vendorLockFile, vendorLock, 0644)
+}
+
+// processAtmosVendorSource pulls one source from the `vendor.yaml` file into a staging folder, calculates the checksum of the pulled files,
+// and then copies the files to all the targets (`pull` command) or compares the files with the targets (`diff` command).
+// If the resolved URI did not change since the previous pull and the files in all the targets match the checksum in the lock file,
+// the `pull` command skips the source
+func processAtmosVendorSource(
+	cliConfig cfg.CliConfiguration,
+	index int,
+	source cfg.AtmosVendorSource,
+	vendorConfigPath string,
+	previousLockedSource *cfg.AtmosVendorLockSource,
+	dryRun bool,
+	vendorCommand string,
+) (cfg.AtmosVendorLockSource, bool, error) {
+
+	lockedSource := cfg.AtmosVendorLockSource{
+		Component: source.Component,
+		Source:    source.Source,
+		Version:   source.Version,
+	}
+
+	// Parse 'source' template
+	uri, err := u.ProcessTmpl(fmt.Sprintf("source-%d", index), source.Source, source)
+	if err != nil {
+		return lockedSource, false, err
+	}
+
+	// The lock file keeps the resolved URI before converting local paths to absolute paths, so it does not depend on the location of the repo
+	lockedSource.Uri = uri
+
+	// Check if `uri` is a file path.
+	// If it's a file path, check if it's an absolute path.
+	// If it's not absolute path, join it with the base path (the folder where the `vendor.yaml` file is located) and convert to absolute path.
+	if absPath, err := u.JoinAbsolutePathWithPath(vendorConfigPath, uri); err == nil {
+		uri = absPath
+	}
+
+	lockedSource.Targets, err = processAtmosVendorSourceTargets(index, source)
+	if err != nil {
+		return lockedSource, false, err
+	}
+
+	var targets []string
+	for _, target := range lockedSource.Targets {
+		targets = append(targets, path.Join(vendorConfigPath, target))
+	}
+
+	filter := cfg.VendorComponentSource{
+		IncludedPaths: source.IncludedPaths,
+		ExcludedPaths: source.ExcludedPaths,
+	}
+
+	if vendorCommand == "pull" && !dryRun && previousLockedSource != nil {
+		upToDate, err := isAtmosVendorSourceUpToDate(lockedSource, *previousLockedSource, targets, filter)
+		if err != nil {
+			return lockedSource, false, err
+		}
+		if upToDate {
+			printVendorInfo(vendorCommand, fmt.Sprintf("Skipping '%s' since the files in %s match the checksum in the lock file\n",
+				uri,
+				strings.Join(lockedSource.Targets, ", "),
+			))
+			return *previousLockedSource, false, nil
+		}
+	}
+
+	printVendorInfo(vendorCommand, fmt.Sprintf("Pulling sources from '%s' and writing to %s\n",
+		uri,
+		strings.Join(lockedSource.Targets, ", "),
+	))
+
+	if dryRun {
+		return lockedSource, false, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
+	if err != nil {
+		return lockedSource, false, err
+	}
+
+	defer removeTempDir(tempDir)
+
+	// The `diff` command always compares the targets with the upstream sources, never with a cached copy
+	if vendorCommand == "diff" {
+		cliConfig.Vendor.Cache.Enabled = false
+	}
+
+	// Download the source (or get it from the cache)
+	sourceDir, err := downloadVendorSourceDir(cliConfig, uri, source.Version, tempDir)
+	if err != nil {
+		return lockedSource, false, err
+	}
+
+	// Copy the files from the source folder into the staging folder with skipping of some files.
+	// The checksum is calculated from the staging folder, so it covers exactly the files that are written to the targets
+	stagingDir := path.Join(tempDir, "staging")
+
+	if err = copyVendorComponentSource(filter, sourceDir, stagingDir, vendorCommand); err != nil {
+		return lockedSource, false, err
+	}
+
+	lockedSource.Checksum, err = checksumVendorFolder(stagingDir, cfg.VendorComponentSource{})
+	if err != nil {
+		return lockedSource, false, err
+	}
+
+	drifted := false
+
+	for i, target := range targets {
+		if vendorCommand == "diff" {
+			diff, changedFiles, err := diffVendorFolders(stagingDir, target)
+			if err != nil {
+				return lockedSource, false, err
+			}
+			if len(changedFiles) > 0 {
+				fmt.Println(diff)
+				drifted = true
+			} else {
+				printVendorInfo(vendorCommand, fmt.Sprintf("The target '%s' is up to date with the upstream sources\n", lockedSource.Targets[i]))
+			}
+			continue
+		}
+
+		copyOptions := cp.Options{
+			PreserveTimes: false,
+			PreserveOwner: false,
+		}
+
+		if err = cp.Copy(stagingDir, target, copyOptions); err != nil {
+			return lockedSource, false, err
+		}
+	}
+
+	return lockedSource, drifted, nil
+}
+
+// isAtmosVendorSourceUpToDate checks if the resolved URI and the targets of the source did not change since the previous pull,
+// and the files in all the targets match the checksum in the lock file
+func isAtmosVendorSourceUpToDate(
+	lockedSource cfg.AtmosVendorLockSource,
+	previousLockedSource cfg.AtmosVendorLockSource,
+	targets []string,
+	filter cfg.VendorComponentSource,
+) (bool, error) {
+
+	if previousLockedSource.Checksum == "" || previousLockedSource.Uri != lockedSource.Uri ||
+		!reflect.DeepEqual(previousLockedSource.Targets, lockedSource.Targets) {
+		return false, nil
+	}
+
+	for _, target := range targets {
+		checksum, err := checksumVendorFolder(target, filter)
+		if err != nil {
+			return false, err
+		}
+		if checksum != previousLockedSource.Checksum {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// processAtmosVendorSourceTargets processes the Go templates in the targets of the source (e.g. `{{.Version}}`)
+func processAtmosVendorSourceTargets(index int, source cfg.AtmosVendorSource) ([]string, error) {
+	var targets []string
+
+	for _, t := range source.Targets {
+		target, err := u.ProcessTmpl(fmt.Sprintf("target-%d", index), t, source)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// atmosVendorSourceKey returns the key to identify the source in the lock file.
+// The same component or source can be vendored more than once (e.g. different versions into different targets),
+// so the key consists of the component, the source and the targets
+func atmosVendorSourceKey(component string, source string, targets []string) string {
+	return strings.Join(append([]string{component, source}, targets...), "\x00")
+}
+
+// atmosVendorSourceDescription returns the description of the source to show in the messages
+func atmosVendorSourceDescription(source cfg.AtmosVendorSource, targets []string) string {
+	if source.Component != "" {
+		return fmt.Sprintf("the component '%s' (targets: %s)", source.Component, strings.Join(targets, ", "))
+	}
+	return fmt.Sprintf("the source '%s' (targets: %s)", source.Source, strings.Join(targets, ", "))
+}
@@ -2,14 +2,18 @@ package exec
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"fmt"
-	"github.com/hashicorp/go-getter"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
+	"io"
+	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -55,6 +59,31 @@ func ExecuteVendorCommand(cmd *cobra.Command, args []string, vendorCommand strin
 		return fmt.Errorf("either '--component' or '--stack' parameter needs to be provided, but not both")
 	}
 
+	tags, err := flags.GetString("tags")
+	if err != nil {
+		return err
+	}
+
+	if component == "" && stack == "" {
+		// Process the repository-level `vendor.yaml` vendor config file
+		vendorConfigFile := path.Join(cliConfig.BasePath, cfg.AtmosVendorConfigFileName)
+		if !u.FileExists(vendorConfigFile) {
+			return fmt.Errorf("either '--component' or '--stack' parameter needs to be provided, "+
+				"or the vendor config file '%s' must exist", vendorConfigFile)
+		}
+
+		var tagsList []string
+		if tags != "" {
+			tagsList = strings.Split(tags, ",")
+		}
+
+		return ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, tagsList, dryRun, vendorCommand)
+	}
+
+	if tags != "" {
+		return fmt.Errorf("'--tags' parameter can only be used with the '%s' vendor config file", cfg.AtmosVendorConfigFileName)
+	}
+
 	if component != "" {
 		// Process component vendoring
 		componentType, err := flags.GetString("type")
@@ -71,10 +100,10 @@ func ExecuteVendorCommand(cmd *cobra.Command, args []string, vendorCommand strin
 			return err
 		}
 
-		return ExecuteComponentVendorCommandInternal(componentConfig.Spec, component, componentPath, dryRun, vendorCommand)
+		return ExecuteComponentVendorCommandInternal(cliConfig, componentConfig.Spec, component, componentPath, dryRun, vendorCommand)
 	} else {
 		// Process stack vendoring
-		return ExecuteStackVendorCommandInternal(stack, dryRun, vendorCommand)
+		return ExecuteStackVendorCommandInternal(cliConfig, stack, dryRun, vendorCommand)
 	}
 }
 
@@ -131,6 +160,7 @@ func ReadAndProcessComponentConfigFile(cliConfig cfg.CliConfiguration, component
 // https://www.allee.xyz/en/posts/getting-started-with-go-getter
 // https://github.com/otiai10/copy
 func ExecuteComponentVendorCommandInternal(
+	cliConfig cfg.CliConfiguration,
 	vendorComponentSpec cfg.VendorComponentSpec,
 	component string,
 	componentPath string,
@@ -138,242 +168,675 @@ func ExecuteComponentVendorCommandInternal(
 	vendorCommand string,
 ) error {
 
-	var tempDir string
-	var err error
-	var t *template.Template
-	var uri string
+	switch vendorCommand {
+	case "pull":
+		return pullComponentSources(cliConfig, vendorComponentSpec, component, componentPath, componentPath, dryRun, vendorCommand)
+	case "diff":
+		return diffComponentSources(cliConfig, vendorComponentSpec, component, componentPath, dryRun)
+	default:
+		return fmt.Errorf("invalid vendor command '%s'. Supported commands: pull, diff", vendorCommand)
+	}
+}
 
-	if vendorCommand == "pull" {
-		if vendorComponentSpec.Source.Uri == "" {
-			return errors.New("'uri' must be specified in 'source.uri' in the 'component.yaml' file")
+// pullComponentSources pulls the component sources and mixins defined in `component.yaml` and writes them to the target folder.
+// `componentPath` is used to resolve the relative file paths in the source and mixin URIs.
+// The mixins are pulled concurrently (up to `vendor.max_concurrency`)
+func pullComponentSources(
+	cliConfig cfg.CliConfiguration,
+	vendorComponentSpec cfg.VendorComponentSpec,
+	component string,
+	componentPath string,
+	targetPath string,
+	dryRun bool,
+	vendorCommand string,
+) error {
+
+	uri, err := buildVendorSourceUri(vendorComponentSpec.Source, componentPath)
+	if err != nil {
+		return err
+	}
+
+	printVendorInfo(vendorCommand, fmt.Sprintf("Pulling sources for the component '%s' from '%s' and writing to '%s'\n",
+		component,
+		uri,
+		targetPath,
+	))
+
+	if !dryRun {
+		// Create temp folder
+		// We are using a temp folder for the following reasons:
+		// 1. 'git' does not clone into an existing folder (and we have the existing component folder with `component.yaml` in it)
+		// 2. We have the option to skip some files we don't need and include only the files we need when copying from the temp folder to the destination folder
+		// ioutil.TempDir is deprecated. As of Go 1.17, this function simply calls os.MkdirTemp
+		tempDir, err := os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
+		if err != nil {
+			return err
+		}
+
+		defer removeTempDir(tempDir)
+
+		// Download the source into the temp folder (or use the cached source)
+		sourceDir, err := downloadVendorSourceDir(cliConfig, uri, vendorComponentSpec.Source.Version, tempDir)
+		if err != nil {
+			return err
+		}
+
+		// Copy from the source folder to the staging folder with skipping of some files
+		stagingDir := path.Join(tempDir, "staging")
+		if err = copyVendorComponentSource(vendorComponentSpec.Source, sourceDir, stagingDir, vendorCommand); err != nil {
+			return err
 		}
 
-		// Parse 'uri' template
-		if vendorComponentSpec.Source.Version != "" {
-			t, err = template.New(fmt.Sprintf("source-uri-%s", vendorComponentSpec.Source.Version)).Parse(vendorComponentSpec.Source.Uri)
+		// Skip writing the files if the checksum of the files in the target folder matches the checksum of the pulled files
+		files, err := listVendorFiles(stagingDir, cfg.VendorComponentSource{})
+		if err != nil {
+			return err
+		}
+
+		stagingChecksum, err := checksumVendorFiles(stagingDir, files)
+		if err != nil {
+			return err
+		}
+
+		targetChecksum, err := checksumVendorFiles(targetPath, files)
+		if err != nil {
+			return err
+		}
+
+		if stagingChecksum == targetChecksum {
+			printVendorInfo(vendorCommand, fmt.Sprintf("Skipping writing the sources for the component '%s' since the files in '%s' are up to date (%s)\n",
+				component,
+				targetPath,
+				stagingChecksum,
+			))
+		} else if err = cp.Copy(stagingDir, targetPath); err != nil {
+			return err
+		}
+	}
+
+	// Process mixins
+	if len(vendorComponentSpec.Mixins) > 0 {
+		_, _ = fmt.Fprintln(vendorOutput(vendorCommand))
+
+		g := new(errgroup.Group)
+		g.SetLimit(vendorMaxConcurrency(cliConfig))
+
+		for _, mixin := range vendorComponentSpec.Mixins {
+			mixinUri, err := buildVendorMixinUri(mixin, componentPath)
 			if err != nil {
+				_ = g.Wait()
 				return err
 			}
 
-			var tpl bytes.Buffer
-			err = t.Execute(&tpl, vendorComponentSpec.Source)
-			if err != nil {
-				return err
+			printVendorInfo(vendorCommand, fmt.Sprintf("Pulling the mixin '%s' for the component '%s' and writing to '%s'\n",
+				mixinUri,
+				component,
+				path.Join(targetPath, mixin.Filename),
+			))
+
+			if !dryRun {
+				mixin := mixin
+				g.Go(func() error {
+					return pullComponentMixin(cliConfig, mixin, mixinUri, targetPath)
+				})
 			}
+		}
 
-			uri = tpl.String()
-		} else {
-			uri = vendorComponentSpec.Source.Uri
+		if err = g.Wait(); err != nil {
+			return err
 		}
+	}
 
-		// Check if `uri` is a file path.
-		// If it's a file path, check if it's an absolute path.
-		// If it's not absolute path, join it with the base path (component dir) and convert to absolute path.
-		if absPath, err := u.JoinAbsolutePathWithPath(componentPath, uri); err == nil {
-			uri = absPath
+	return nil
+}
+
+// buildVendorSourceUri processes the 'uri' template of the component source and converts a relative file path to an absolute path
+func buildVendorSourceUri(source cfg.VendorComponentSource, componentPath string) (string, error) {
+	if source.Uri == "" {
+		return "", errors.New("'uri' must be specified in 'source.uri' in the 'component.yaml' file")
+	}
+
+	uri := source.Uri
+
+	// Parse 'uri' template
+	if source.Version != "" {
+		t, err := template.New(fmt.Sprintf("source-uri-%s", source.Version)).Parse(source.Uri)
+		if err != nil {
+			return "", err
 		}
 
-		u.PrintInfo(fmt.Sprintf("Pulling sources for the component '%s' from '%s' and writing to '%s'\n",
-			component,
-			uri,
-			componentPath,
-		))
+		var tpl bytes.Buffer
+		err = t.Execute(&tpl, source)
+		if err != nil {
+			return "", err
+		}
 
-		if !dryRun {
-			// Create temp folder
-			// We are using a temp folder for the following reasons:
-			// 1. 'git' does not clone into an existing folder (and we have the existing component folder with `component.yaml` in it)
-			// 2. We have the option to skip some files we don't need and include only the files we need when copying from the temp folder to the destination folder
-			// ioutil.TempDir is deprecated. As of Go 1.17, this function simply calls os.MkdirTemp
-			tempDir, err = os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
-			if err != nil {
-				return err
-			}
+		uri = tpl.String()
+	}
+
+	// Check if `uri` is a file path.
+	// If it's a file path, check if it's an absolute path.
+	// If it's not absolute path, join it with the base path (component dir) and convert to absolute path.
+	if absPath, err := u.JoinAbsolutePathWithPath(componentPath, uri); err == nil {
+		uri = absPath
+	}
+
+	return uri, nil
+}
+
+// buildVendorMixinUri processes the 'uri' template of the component mixin and converts a relative file path to an absolute path
+func buildVendorMixinUri(mixin cfg.VendorComponentMixins, componentPath string) (string, error) {
+	if mixin.Uri == "" {
+		return "", errors.New("'uri' must be specified for each 'mixin' in the 'component.yaml' file")
+	}
 
-			defer removeTempDir(tempDir)
+	if mixin.Filename == "" {
+		return "", errors.New("'filename' must be specified for each 'mixin' in the 'component.yaml' file")
+	}
+
+	uri := mixin.Uri
+
+	// Parse 'uri' template
+	if mixin.Version != "" {
+		t, err := template.New(fmt.Sprintf("mixin-uri-%s", mixin.Version)).Parse(mixin.Uri)
+		if err != nil {
+			return "", err
+		}
 
-			// Download the source into the temp folder
-			client := &getter.Client{
-				Ctx: context.Background(),
-				// Define the destination to where the files will be stored. This will create the directory if it doesn't exist
-				Dst: tempDir,
-				// Source
-				Src:  uri,
-				Mode: getter.ClientModeDir,
+		var tpl bytes.Buffer
+		err = t.Execute(&tpl, mixin)
+		if err != nil {
+			return "", err
+		}
+
+		uri = tpl.String()
+	}
+
+	// Check if `uri` is a file path.
+	// If it's a file path, check if it's an absolute path.
+	// If it's not absolute path, join it with the base path (component dir) and convert to absolute path.
+	if absPath, err := u.JoinAbsolutePathWithPath(componentPath, uri); err == nil {
+		uri = absPath
+	}
+
+	return uri, nil
+}
+
+// copyVendorComponentSource copies the downloaded component source from the temp folder to the destination folder,
+// skipping the files according to the 'included_paths' and 'excluded_paths' patterns
+func copyVendorComponentSource(source cfg.VendorComponentSource, tempDir string, targetPath string, vendorCommand string) error {
+	out := vendorOutput(vendorCommand)
+
+	copyOptions := cp.Options{
+		// Skip specifies which files should be skipped
+		Skip: func(srcinfo os.FileInfo, src, dest string) (bool, error) {
+			if strings.HasSuffix(src, ".git") {
+				return true, nil
 			}
 
-			if err = client.Get(); err != nil {
-				return err
+			trimmedSrc := u.TrimBasePathFromPath(tempDir+"/", src)
+
+			// Exclude the files that match the 'excluded_paths' patterns
+			// It supports POSIX-style Globs for file names/paths (double-star `**` is supported)
+			// https://en.wikipedia.org/wiki/Glob_(programming)
+			// https://github.com/bmatcuk/doublestar#patterns
+			for _, excludePath := range source.ExcludedPaths {
+				excludeMatch, err := u.PathMatch(excludePath, src)
+				if err != nil {
+					return true, err
+				} else if excludeMatch {
+					// If the file matches ANY of the 'excluded_paths' patterns, exclude the file
+					_, _ = fmt.Fprintf(out, "Excluding the file '%s' since it matches the '%s' pattern from 'excluded_paths'\n",
+						trimmedSrc,
+						excludePath,
+					)
+					return true, nil
+				}
 			}
 
-			// Copy from the temp folder to the destination folder with skipping of some files
-			copyOptions := cp.Options{
-				// Skip specifies which files should be skipped
-				Skip: func(srcinfo os.FileInfo, src, dest string) (bool, error) {
-					if strings.HasSuffix(src, ".git") {
-						return true, nil
+			// Only include the files that match the 'included_paths' patterns (if any pattern is specified)
+			if len(source.IncludedPaths) > 0 {
+				anyMatches := false
+				for _, includePath := range source.IncludedPaths {
+					includeMatch, err := u.PathMatch(includePath, src)
+					if err != nil {
+						return true, err
+					} else if includeMatch {
+						// If the file matches ANY of the 'included_paths' patterns, include the file
+						_, _ = fmt.Fprintf(out, "Including '%s' since it matches the '%s' pattern from 'included_paths'\n",
+							trimmedSrc,
+							includePath,
+						)
+						anyMatches = true
+						break
 					}
+				}
 
-					trimmedSrc := u.TrimBasePathFromPath(tempDir+"/", src)
-
-					// Exclude the files that match the 'excluded_paths' patterns
-					// It supports POSIX-style Globs for file names/paths (double-star `**` is supported)
-					// https://en.wikipedia.org/wiki/Glob_(programming)
-					// https://github.com/bmatcuk/doublestar#patterns
-					for _, excludePath := range vendorComponentSpec.Source.ExcludedPaths {
-						excludeMatch, err := u.PathMatch(excludePath, src)
-						if err != nil {
-							return true, err
-						} else if excludeMatch {
-							// If the file matches ANY of the 'excluded_paths' patterns, exclude the file
-							fmt.Printf("Excluding the file '%s' since it matches the '%s' pattern from 'excluded_paths'\n",
-								trimmedSrc,
-								excludePath,
-							)
-							return true, nil
-						}
-					}
+				if anyMatches {
+					return false, nil
+				} else {
+					_, _ = fmt.Fprintf(out, "Excluding '%s' since it does not match any pattern from 'included_paths'\n", trimmedSrc)
+					return true, nil
+				}
+			}
 
-					// Only include the files that match the 'included_paths' patterns (if any pattern is specified)
-					if len(vendorComponentSpec.Source.IncludedPaths) > 0 {
-						anyMatches := false
-						for _, includePath := range vendorComponentSpec.Source.IncludedPaths {
-							includeMatch, err := u.PathMatch(includePath, src)
-							if err != nil {
-								return true, err
-							} else if includeMatch {
-								// If the file matches ANY of the 'included_paths' patterns, include the file
-								fmt.Printf("Including '%s' since it matches the '%s' pattern from 'included_paths'\n",
-									trimmedSrc,
-									includePath,
-								)
-								anyMatches = true
-								break
-							}
-						}
+			// If 'included_paths' is not provided, include all files that were not excluded
+			_, _ = fmt.Fprintf(out, "Including '%s'\n", u.TrimBasePathFromPath(tempDir+"/", src))
+			return false, nil
+		},
 
-						if anyMatches {
-							return false, nil
-						} else {
-							fmt.Printf("Excluding '%s' since it does not match any pattern from 'included_paths'\n", trimmedSrc)
-							return true, nil
-						}
-					}
+		// Preserve the atime and the mtime of the entries
+		// On linux we can preserve only up to 1 millisecond accuracy
+		PreserveTimes: false,
 
-					// If 'included_paths' is not provided, include all files that were not excluded
-					fmt.Printf("Including '%s'\n", u.TrimBasePathFromPath(tempDir+"/", src))
-					return false, nil
-				},
+		// Preserve the uid and the gid of all entries
+		PreserveOwner: false,
+	}
+
+	return cp.Copy(tempDir, targetPath, copyOptions)
+}
+
+// pullComponentMixin downloads the mixin into a temp folder (or uses the cached mixin) and copies it to the destination folder
+func pullComponentMixin(cliConfig cfg.CliConfiguration, mixin cfg.VendorComponentMixins, uri string, targetPath string) error {
+	tempDir, err := os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
+	if err != nil {
+		return err
+	}
+
+	defer removeTempDir(tempDir)
+
+	// Download the mixin into the temp file
+	if err = downloadVendorFile(cliConfig, uri, path.Join(tempDir, mixin.Filename)); err != nil {
+		return err
+	}
+
+	// Copy from the temp folder to the destination folder
+	copyOptions := cp.Options{
+		// Preserve the atime and the mtime of the entries
+		PreserveTimes: false,
+
+		// Preserve the uid and the gid of all entries
+		PreserveOwner: false,
+
+		// OnSymlink specifies what to do on symlink
+		// Override the destination file if it already exists
+		// Prevent the error:
+		// symlink components/terraform/mixins/context.tf components/terraform/infra/vpc-flow-logs-bucket/context.tf: file exists
+		OnSymlink: func(src string) cp.SymlinkAction {
+			return cp.Deep
+		},
+	}
 
-				// Preserve the atime and the mtime of the entries
-				// On linux we can preserve only up to 1 millisecond accuracy
-				PreserveTimes: false,
+	return cp.Copy(tempDir, targetPath, copyOptions)
+}
 
-				// Preserve the uid and the gid of all entries
-				PreserveOwner: false,
+// printVendorInfo prints the progress message of the vendor command.
+// The `diff` command prints the progress messages to std.Error, so only the diff is printed to std.Output,
+// and it can be piped to `patch` or parsed
+func printVendorInfo(vendorCommand string, message string) {
+	if vendorCommand == "diff" {
+		u.PrintInfoToStdError(message)
+		return
+	}
+	u.PrintInfo(message)
+}
+
+// vendorOutput returns the writer for the progress messages of the vendor command (std.Error for the `diff` command)
+func vendorOutput(vendorCommand string) io.Writer {
+	if vendorCommand == "diff" {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// vendorMaxConcurrency returns the maximum number of components, sources and mixins processed concurrently
+func vendorMaxConcurrency(cliConfig cfg.CliConfiguration) int {
+	if cliConfig.Vendor.MaxConcurrency < 1 {
+		return 1
+	}
+	return cliConfig.Vendor.MaxConcurrency
+}
+
+// diffComponentSources pulls the component sources and mixins into a temp folder
+// and prints a unified diff between the upstream files and the files in the component folder.
+// It returns an error if the vendored component differs from the upstream (drift is detected).
+// The files in the component folder that are not part of the upstream sources (e.g. `component.yaml`) are not compared
+func diffComponentSources(
+	cliConfig cfg.CliConfiguration,
+	vendorComponentSpec cfg.VendorComponentSpec,
+	component string,
+	componentPath string,
+	dryRun bool,
+) error {
+
+	stagingDir, err := os.MkdirTemp("", strconv.FormatInt(time.Now().Unix(), 10))
+	if err != nil {
+		return err
+	}
+
+	defer removeTempDir(stagingDir)
+
+	// Always compare the component folder with the upstream sources, never with a cached copy
+	cliConfig.Vendor.Cache.Enabled = false
+
+	if err = pullComponentSources(cliConfig, vendorComponentSpec, component, componentPath, stagingDir, dryRun, "diff"); err != nil {
+		return err
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(vendorOutput("diff"))
+	printVendorInfo("diff", fmt.Sprintf("Comparing the upstream sources for the component '%s' with the component folder '%s'\n", component, componentPath))
+
+	diff, changedFiles, err := diffVendorFolders(stagingDir, componentPath)
+	if err != nil {
+		return err
+	}
+
+	if len(changedFiles) == 0 {
+		printVendorInfo("diff", fmt.Sprintf("The component '%s' is up to date with the upstream sources\n", component))
+		return nil
+	}
+
+	fmt.Println(diff)
+
+	return &VendorDriftError{
+		Drifts: []string{fmt.Sprintf("the component '%s' in the folder '%s': %s", component, componentPath, strings.Join(changedFiles, ", "))},
+	}
+}
+
+// VendorDriftError is returned by the `vendor diff` command if the vendored files differ from the upstream sources
+type VendorDriftError struct {
+	// The descriptions of the drifted components and sources
+	Drifts []string
+}
+
+func (e *VendorDriftError) Error() string {
+	return fmt.Sprintf("the vendored files differ from the upstream sources for:\n%s", strings.Join(e.Drifts, "\n"))
+}
+
+// joinVendorErrors joins the errors from processing all the components or sources.
+// The drifts from all the `VendorDriftError` errors are combined into one `VendorDriftError`.
+// If there are no other errors, the `VendorDriftError` is returned as is, so the caller can detect the drift by the type of the error
+func joinVendorErrors(errs []error) error {
+	var drift *VendorDriftError
+	var otherErrs []error
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var driftErr *VendorDriftError
+		if errors.As(err, &driftErr) {
+			if drift == nil {
+				drift = &VendorDriftError{}
 			}
+			drift.Drifts = append(drift.Drifts, driftErr.Drifts...)
+			continue
+		}
+
+		otherErrs = append(otherErrs, err)
+	}
 
-			if err = cp.Copy(tempDir, componentPath, copyOptions); err != nil {
+	if len(otherErrs) == 0 {
+		if drift == nil {
+			return nil
+		}
+		return drift
+	}
+
+	if drift != nil {
+		otherErrs = append(otherErrs, drift)
+	}
+
+	return errors.Join(otherErrs...)
+}
+
+// diffVendorFolders compares all the files in the upstream folder with the corresponding files in the component folder.
+// It returns the unified diff and the list of the changed files (relative to the folders)
+func diffVendorFolders(upstreamPath string, componentPath string) (string, []string, error) {
+	var changedFiles []string
+	var diff strings.Builder
+
+	err := filepath.WalkDir(upstreamPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(upstreamPath, p)
+		if err != nil {
+			return err
+		}
+
+		upstreamContent, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		localFile := path.Join(componentPath, relPath)
+		localContent := []byte{}
+		localFileName := path.Join("local", relPath)
+
+		if u.FileExists(localFile) {
+			localContent, err = os.ReadFile(localFile)
+			if err != nil {
 				return err
 			}
+		} else {
+			localFileName = "/dev/null"
 		}
 
-		// Process mixins
-		if len(vendorComponentSpec.Mixins) > 0 {
-			fmt.Println()
+		if bytes.Equal(upstreamContent, localContent) {
+			return nil
+		}
 
-			for _, mixin := range vendorComponentSpec.Mixins {
-				if mixin.Uri == "" {
-					return errors.New("'uri' must be specified for each 'mixin' in the 'component.yaml' file")
-				}
+		changedFiles = append(changedFiles, relPath)
+
+		if bytes.IndexByte(upstreamContent, 0) >= 0 || bytes.IndexByte(localContent, 0) >= 0 {
+			diff.WriteString(fmt.Sprintf("Binary files %s and %s differ\n", path.Join("upstream", relPath), localFileName))
+			return nil
+		}
+
+		unifiedDiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(upstreamContent)),
+			B:        difflib.SplitLines(string(localContent)),
+			FromFile: path.Join("upstream", relPath),
+			ToFile:   localFileName,
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+
+		diff.WriteString(unifiedDiff)
+		return nil
+	})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return diff.String(), changedFiles, nil
+}
 
-				if mixin.Filename == "" {
-					return errors.New("'filename' must be specified for each 'mixin' in the 'component.yaml' file")
+// ExecuteStackVendorCommandInternal executes a stack vendor command.
+// It finds all the terraform and helmfile components used in the stack (including the base components
+// from `metadata.component`, `metadata.inherits` and the inheritance chain),
+// and executes the vendor command for each component folder that has a `component.yaml` vendor config file
+func ExecuteStackVendorCommandInternal(
+	cliConfig cfg.CliConfiguration,
+	stack string,
+	dryRun bool,
+	vendorCommand string,
+) error {
+
+	stacksMap, _, err := FindStacksMap(cliConfig, false)
+	if err != nil {
+		return err
+	}
+
+	componentFolders, err := FindComponentFoldersInStack(cliConfig, stacksMap, stack)
+	if err != nil {
+		return err
+	}
+
+	if len(componentFolders) == 0 {
+		return fmt.Errorf("no components found in the stack '%s'", stack)
+	}
+
+	componentBasePaths := map[string]string{
+		"terraform": cliConfig.Components.Terraform.BasePath,
+		"helmfile":  cliConfig.Components.Helmfile.BasePath,
+	}
+
+	type vendoredComponent struct {
+		component     string
+		componentPath string
+		spec          cfg.VendorComponentSpec
+	}
+
+	var vendoredComponents []vendoredComponent
+
+	for _, componentType := range []string{"terraform", "helmfile"} {
+		for _, component := range componentFolders[componentType] {
+			// Not all components are vendored. Skip the component folders without the `component.yaml` vendor config file
+			componentConfigFile := path.Join(cliConfig.BasePath, componentBasePaths[componentType], component, cfg.ComponentConfigFileName)
+			if !u.FileExists(componentConfigFile) {
+				if cliConfig.Logs.Verbose {
+					printVendorInfo(vendorCommand, fmt.Sprintf("Skipping the %s component '%s': vendor config file '%s' does not exist\n",
+						componentType, component, cfg.ComponentConfigFileName))
 				}
+				continue
+			}
 
-				// Parse 'uri' template
-				if mixin.Version != "" {
-					t, err = template.New(fmt.Sprintf("mixin-uri-%s", mixin.Version)).Parse(mixin.Uri)
-					if err != nil {
-						return err
-					}
+			componentConfig, componentPath, err := ReadAndProcessComponentConfigFile(cliConfig, component, componentType)
+			if err != nil {
+				return err
+			}
 
-					var tpl bytes.Buffer
-					err = t.Execute(&tpl, mixin)
-					if err != nil {
-						return err
-					}
+			vendoredComponents = append(vendoredComponents, vendoredComponent{component: component, componentPath: componentPath, spec: componentConfig.Spec})
+		}
+	}
 
-					uri = tpl.String()
-				} else {
-					uri = mixin.Uri
+	// Process the components concurrently (up to `vendor.max_concurrency`).
+	// All the components are processed even if some of them fail, so the drift of all the components is reported by the `diff` command.
+	// The goroutines write the errors to their own items (by the index of the component)
+	errs := make([]error, len(vendoredComponents))
+
+	g := new(errgroup.Group)
+	g.SetLimit(vendorMaxConcurrency(cliConfig))
+
+	for i, c := range vendoredComponents {
+		i, c := i, c
+		g.Go(func() error {
+			errs[i] = ExecuteComponentVendorCommandInternal(cliConfig, c.spec, c.component, c.componentPath, dryRun, vendorCommand)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return joinVendorErrors(errs)
+}
+
+// FindComponentFoldersInStack returns a map of component types to the sorted lists of distinct component folders
+// (relative to the components base paths) used by the components in the stack.
+// The stack can be specified by its logical name (e.g. `tenant1-ue2-dev`) or by the stack file name
+func FindComponentFoldersInStack(
+	cliConfig cfg.CliConfiguration,
+	stacksMap map[string]any,
+	stack string,
+) (map[string][]string, error) {
+
+	result := map[string][]string{}
+	stackFound := false
+
+	for stackFileName, stackSection := range stacksMap {
+		componentsSection, ok := stackSection.(map[any]any)["components"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, componentType := range []string{"terraform", "helmfile"} {
+			componentTypeSection, ok := componentsSection[componentType].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			for componentName, compSection := range componentTypeSection {
+				componentSection, ok := compSection.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("invalid 'components.%s.%s' section in the file '%s'", componentType, componentName, stackFileName)
 				}
 
-				// Check if `uri` is a file path.
-				// If it's a file path, check if it's an absolute path.
-				// If it's not absolute path, join it with the base path (component dir) and convert to absolute path.
-				if absPath, err := u.JoinAbsolutePathWithPath(componentPath, uri); err == nil {
-					uri = absPath
+				stackName := stackFileName
+				if varsSection, ok := componentSection["vars"].(map[any]any); ok {
+					context := cfg.GetContextFromVars(varsSection)
+					if contextPrefix, err := cfg.GetContextPrefix(stackFileName, context, cliConfig.Stacks.NamePattern, stackFileName); err == nil {
+						stackName = contextPrefix
+					}
 				}
 
-				u.PrintInfo(fmt.Sprintf("Pulling the mixin '%s' for the component '%s' and writing to '%s'\n",
-					uri,
-					component,
-					path.Join(componentPath, mixin.Filename),
-				))
+				if stack != stackName && stack != stackFileName {
+					continue
+				}
 
-				if !dryRun {
-					err = os.RemoveAll(tempDir)
-					if err != nil {
-						return err
-					}
+				stackFound = true
 
-					// Download the mixin into the temp file
-					client := &getter.Client{
-						Ctx:  context.Background(),
-						Dst:  path.Join(tempDir, mixin.Filename),
-						Src:  uri,
-						Mode: getter.ClientModeFile,
-					}
+				// The component itself and all the components it inherits from
+				atmosComponents := []string{componentName}
 
-					if err = client.Get(); err != nil {
-						return err
+				if inheritanceChain, ok := componentSection["inheritance"].([]string); ok {
+					atmosComponents = append(atmosComponents, inheritanceChain...)
+				}
+
+				if metadataSection, ok := componentSection["metadata"].(map[any]any); ok {
+					if inheritList, ok := metadataSection["inherits"].([]any); ok {
+						for _, v := range inheritList {
+							if baseComponent, ok := v.(string); ok {
+								atmosComponents = append(atmosComponents, baseComponent)
+							}
+						}
 					}
+				}
 
-					// Copy from the temp folder to the destination folder
-					copyOptions := cp.Options{
-						// Preserve the atime and the mtime of the entries
-						PreserveTimes: false,
-
-						// Preserve the uid and the gid of all entries
-						PreserveOwner: false,
-
-						// OnSymlink specifies what to do on symlink
-						// Override the destination file if it already exists
-						// Prevent the error:
-						// symlink components/terraform/mixins/context.tf components/terraform/infra/vpc-flow-logs-bucket/context.tf: file exists
-						OnSymlink: func(src string) cp.SymlinkAction {
-							return cp.Deep
-						},
+				for _, atmosComponent := range u.UniqueStrings(atmosComponents) {
+					atmosComponentSection, ok := componentTypeSection[atmosComponent].(map[string]any)
+					if !ok {
+						continue
 					}
 
-					if err = cp.Copy(tempDir, componentPath, copyOptions); err != nil {
-						return err
+					// `component` points to the component implementation (the terraform or helmfile folder).
+					// It's set from `metadata.component` (or the top-level `component` attribute) by the stack processor
+					componentFolder := atmosComponent
+					if folder, ok := atmosComponentSection["component"].(string); ok && folder != "" {
+						componentFolder = folder
 					}
+
+					u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found the %s component folder '%s' used by the component '%s' in the stack '%s'",
+						componentType, componentFolder, componentName, stackName))
+
+					result[componentType] = append(result[componentType], componentFolder)
 				}
 			}
 		}
 	}
 
-	return nil
-}
+	if !stackFound {
+		return nil, fmt.Errorf("could not find the stack '%s'", stack)
+	}
 
-// ExecuteStackVendorCommandInternal executes a stack vendor command
-// TODO: implement this
-func ExecuteStackVendorCommandInternal(
-	stack string,
-	dryRun bool,
-	vendorCommand string,
-) error {
-	return fmt.Errorf("command 'atmos vendor %s --stack <stack>' is not implemented yet", vendorCommand)
+	for componentType, folders := range result {
+		folders = u.UniqueStrings(folders)
+		sort.Strings(folders)
+		result[componentType] = folders
+	}
+
+	return result, nil
 }
@@ -3,14 +3,10 @@ package exec
 import (
 	"errors"
 	"fmt"
-	"os"
-	"path"
-	"path/filepath"
+	"strings"
 
 	cfg "github.com/cloudposse/atmos/pkg/config"
-	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 // ExecuteWorkflowCmd executes a workflow
@@ -53,52 +49,34 @@ func ExecuteWorkflowCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	var workflowPath string
-	if u.IsPathAbsolute(workflowFile) {
-		workflowPath = workflowFile
-	} else {
-		workflowPath = path.Join(cliConfig.BasePath, cliConfig.Workflows.BasePath, workflowFile)
-	}
-
-	// If the file is specified without an extension, use the default extension
-	ext := filepath.Ext(workflowPath)
-	if ext == "" {
-		ext = cfg.DefaultStackConfigFileExtension
-		workflowPath = workflowPath + ext
-	}
-
-	if !u.FileExists(workflowPath) {
-		return fmt.Errorf("file '%s' does not exist", workflowPath)
-	}
-
-	fileContent, err := os.ReadFile(workflowPath)
+	resume, err := flags.GetBool("resume")
 	if err != nil {
 		return err
 	}
 
-	var yamlContent cfg.WorkflowFile
-	var workflowConfig cfg.WorkflowConfig
-	var workflowDefinition cfg.WorkflowDefinition
-
-	if err = yaml.Unmarshal(fileContent, &yamlContent); err != nil {
+	inputFlags, err := flags.GetStringArray("input")
+	if err != nil {
 		return err
 	}
 
-	if i, ok := yamlContent["workflows"]; !ok {
-		return errors.New("a workflow file must be a map with top-level 'workflows:' key")
-	} else {
-		workflowConfig = i
+	inputs := map[string]string{}
+	for _, inputFlag := range inputFlags {
+		name, value, found := strings.Cut(inputFlag, "=")
+		if !found || name == "" {
+			return fmt.Errorf("invalid '--input %s' flag. The inputs must be provided in the format '--input <name>=<value>'", inputFlag)
+		}
+		inputs[name] = value
 	}
 
 	workflow := args[0]
 
-	if i, ok := workflowConfig[workflow]; !ok {
-		return fmt.Errorf("the file '%s' does not have the '%s' workflow defined", workflowPath, workflow)
-	} else {
-		workflowDefinition = i
+	// If the file is not specified, find the workflow in all the workflow files
+	workflowPath, workflowDefinition, err := FindWorkflow(cliConfig, workflow, workflowFile)
+	if err != nil {
+		return err
 	}
 
-	err = ExecuteWorkflow(workflow, workflowPath, &workflowDefinition, dryRun, commandLineStack, fromStep)
+	err = ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, dryRun, commandLineStack, fromStep, inputs, resume)
 	if err != nil {
 		return err
 	}
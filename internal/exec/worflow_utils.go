@@ -1,23 +1,83 @@
 package exec
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"mvdan.cc/sh/v3/syntax"
 
 	cfg "github.com/cloudposse/atmos/pkg/config"
 	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+const (
+	workflowStepStatusPending   = "pending"
+	workflowStepStatusRunning   = "running"
+	workflowStepStatusSucceeded = "succeeded"
+	workflowStepStatusFailed    = "failed"
+	workflowStepStatusContinued = "failed (continue_on_error)"
+	workflowStepStatusSkipped   = "skipped"
+	workflowStepStatusResumed   = "resumed"
+	workflowStepStatusCanceled  = "canceled"
+
+	workflowStepRetryBackoffConstant    = "constant"
+	workflowStepRetryBackoffExponential = "exponential"
+)
 
-	"github.com/samber/lo"
+var (
+	// Mutex to serialize writing the output of the workflow steps executed in parallel
+	workflowOutputLock = &sync.Mutex{}
 )
 
-// ExecuteWorkflow executes an Atmos workflow
+// workflowStepResult holds the status, the number of attempts, the duration and the outputs of the executed workflow step
+type workflowStepResult struct {
+	index     int
+	status    string
+	attempts  int
+	startedAt time.Time
+	duration  time.Duration
+	stdout    string
+	outputs   map[string]any
+	err       error
+}
+
+// workflowStepSettings holds the retry, timeout and error handling settings of the workflow step.
+// The step-level settings override the workflow-level defaults
+type workflowStepSettings struct {
+	maxAttempts     int
+	delay           time.Duration
+	backoff         string
+	maxDelay        time.Duration
+	timeout         time.Duration
+	continueOnError bool
+}
+
+// ExecuteWorkflow executes an Atmos workflow.
+// The steps are executed as a DAG: a step starts when all the steps from its `needs` attribute have succeeded.
+// Up to `max_parallel` steps are executed at the same time (one by default, in the order they are defined).
+// The step `command` and `stack` attributes are Go templates that can reference the workflow inputs (`{{ .inputs.x }}`)
+// and the outputs of the completed steps (`{{ .steps.<name>.outputs.y }}`).
+// The run is recorded in the workflow state file. If `resume` is true, the steps that succeeded in the last run of the workflow
+// (from the same file, with the same stack and inputs) are not executed again
 func ExecuteWorkflow(
+	cliConfig cfg.CliConfiguration,
 	workflow string,
 	workflowPath string,
 	workflowDefinition *cfg.WorkflowDefinition,
 	dryRun bool,
 	commandLineStack string,
 	fromStep string,
+	inputs map[string]string,
+	resume bool,
 ) error {
 	var steps = workflowDefinition.Steps
 
@@ -25,89 +85,826 @@ func ExecuteWorkflow(
 		return fmt.Errorf("workflow '%s' does not have any steps defined", workflow)
 	}
 
-	// Check if the steps have the `name` attribute.
-	// If not, generate a friendly name consisting of a prefix of `step` and followed by the index of the
-	// step (the index starts with 1, so the first generated step name would be `step1`)
+	setDefaultWorkflowStepNames(steps)
+
+	stepIndexes, err := validateWorkflowSteps(workflow, steps)
+	if err != nil {
+		return err
+	}
+
+	stepSettings := make([]workflowStepSettings, len(steps))
 	for index, step := range steps {
-		if step.Name == "" {
-			// When iterating through a slice with a range loop, if elements need to be changed,
-			// changing the returned value from the range is not changing the original slice element.
-			// That return value is a copy of the element.
-			// So doing changes to it will not affect the original elements.
-			// We need to access the element with the index returned from the range iterator and change it there.
-			// https://medium.com/@nsspathirana/common-mistakes-with-go-slices-95f2e9b362a9
-			steps[index].Name = fmt.Sprintf("step%d", index+1)
+		stepSettings[index], err = getWorkflowStepSettings(workflow, workflowDefinition, step)
+		if err != nil {
+			return err
 		}
 	}
 
+	inputValues, err := processWorkflowInputs(workflow, workflowDefinition, inputs)
+	if err != nil {
+		return err
+	}
+
+	// The data for the templates in the step `command` and `stack` attributes.
+	// The outputs of each step are added when the step completes
+	stepsData := map[string]any{}
+	templateData := map[string]any{
+		"inputs": inputValues,
+		"steps":  stepsData,
+	}
+
 	u.PrintInfo(fmt.Sprintf("\nExecuting the workflow '%s' from '%s'\n", workflow, workflowPath))
 
-	err := u.PrintAsYAML(workflowDefinition)
+	err = u.PrintAsYAML(workflowDefinition)
 	if err != nil {
 		return err
 	}
 
-	// If `--from-step` is specified, skip all the previous steps
-	if fromStep != "" {
-		steps = lo.DropWhile[cfg.WorkflowStep](steps, func(step cfg.WorkflowStep) bool {
-			return step.Name != fromStep
-		})
+	results := make([]workflowStepResult, len(steps))
+	for index := range steps {
+		results[index] = workflowStepResult{index: index, status: workflowStepStatusPending}
+	}
 
-		if len(steps) == 0 {
+	if resume && fromStep != "" {
+		return errors.New("the '--resume' and '--from-step' flags can't be used together")
+	}
+
+	stateFile := getWorkflowStateFilePath(cliConfig)
+
+	run := newWorkflowRun(workflow, getWorkflowRunFile(cliConfig, workflowPath), commandLineStack, stringifyWorkflowInputs(inputValues), steps)
+
+	// If `--resume` is specified, skip the steps that succeeded in the last run.
+	// The outputs of the skipped steps are restored from the state file, so they can be used in the templates of the other steps
+	if resume {
+		state, err := ReadWorkflowStateFile(stateFile)
+		if err != nil {
+			return err
+		}
+
+		lastRun, found := findLastWorkflowRun(state, run.Workflow, run.File, run.Stack, run.Inputs)
+		if !found {
+			u.PrintInfo(fmt.Sprintf("No previous runs of the workflow '%s' with the same stack and inputs found. Executing all the steps\n", workflow))
+		} else {
+			u.PrintInfo(fmt.Sprintf("Resuming the workflow '%s' from the run '%s' started at %s\n", workflow, lastRun.Id, lastRun.StartedAt))
+
+			for index, step := range steps {
+				if lastRunStep, ok := workflowRunStepSucceeded(lastRun, step.Name); ok {
+					results[index].status = workflowStepStatusResumed
+					run.Steps[index] = lastRunStep
+					run.Steps[index].Status = workflowStepStatusResumed
+					stepsData[step.Name] = map[string]any{
+						"outputs": lastRunStep.Outputs,
+						"stdout":  "",
+					}
+				}
+			}
+		}
+	}
+
+	// If `--from-step` is specified, skip all the previous steps.
+	// The skipped steps are considered completed when checking the `needs` of the other steps
+	if fromStep != "" {
+		fromStepIndex, ok := stepIndexes[fromStep]
+		if !ok {
 			return fmt.Errorf("invalid '--from-step' flag. Workflow '%s' does not have a step with the name '%s'", workflow, fromStep)
 		}
+
+		for index := 0; index < fromStepIndex; index++ {
+			results[index].status = workflowStepStatusSkipped
+			run.Steps[index].Status = workflowStepStatusSkipped
+		}
 	}
 
-	for stepIdx, step := range steps {
-		var command = strings.TrimSpace(step.Command)
-		var commandType = strings.TrimSpace(step.Type)
+	// The runs are not recorded in dry run mode
+	recordRun := func() {
+		if dryRun {
+			return
+		}
+		if err := saveWorkflowRun(stateFile, run); err != nil {
+			u.PrintErrorToStdError(fmt.Errorf("failed to record the workflow run in the state file '%s': %w", stateFile, err))
+		}
+	}
 
-		u.PrintInfo(fmt.Sprintf("Executing workflow step: %s", command))
+	recordRun()
 
-		if commandType == "" {
-			commandType = "atmos"
+	maxParallel := workflowDefinition.MaxParallel
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	// Each line of the step output is prefixed with the step name if the steps can run in parallel,
+	// or if any step declares `needs` (the steps are executed in the order of their dependencies, so the output must be attributable)
+	prefixOutput := maxParallel > 1 || workflowStepsHaveNeeds(steps)
+
+	// The step `command` and `stack` attributes are processed as Go templates only if the workflow uses inputs or outputs.
+	// Otherwise, the commands are executed as is, so they can contain literal `{{` (e.g. `docker ps --format '{{.Names}}'`)
+	templatesEnabled := workflowUsesTemplates(workflowDefinition, steps)
+
+	done := make(chan workflowStepResult)
+	running := 0
+	var stepErr error
+
+	// completeStep records the result of the step. The template data is only updated here (in the main goroutine),
+	// and the templates are rendered before the steps are started, so the running steps never access it
+	completeStep := func(result workflowStepResult) {
+		results[result.index] = result
+
+		runStep := &run.Steps[result.index]
+		runStep.Status = result.status
+		runStep.Attempts = result.attempts
+		runStep.Outputs = result.outputs
+		if !result.startedAt.IsZero() {
+			runStep.StartedAt = formatWorkflowTime(result.startedAt)
+		}
+		runStep.CompletedAt = formatWorkflowTime(time.Now())
+		if result.err != nil {
+			runStep.Error = result.err.Error()
 		}
 
-		if commandType == "shell" {
-			commandName := fmt.Sprintf("%s-step-%d", workflow, stepIdx)
-			if err := ExecuteShell(command, commandName, ".", []string{}, dryRun, true); err != nil {
-				return err
+		if result.status == workflowStepStatusFailed && stepErr == nil {
+			stepErr = fmt.Errorf("workflow step '%s' failed: %w", steps[result.index].Name, result.err)
+		}
+
+		if result.status == workflowStepStatusSucceeded {
+			stepsData[steps[result.index].Name] = map[string]any{
+				"outputs": result.outputs,
+				"stdout":  result.stdout,
 			}
-		} else if commandType == "atmos" {
-			args := strings.Fields(command)
-
-			var workflowStack = strings.TrimSpace(workflowDefinition.Stack)
-			var stepStack = strings.TrimSpace(step.Stack)
-			var finalStack = ""
-
-			// The workflow `stack` attribute overrides the stack in the `command` (if specified)
-			// The step `stack` attribute overrides the stack in the `command` and the workflow `stack` attribute
-			// The stack defined on the command line (`atmos workflow <name> -f <file> -s <stack>`) has the highest priority,
-			// it overrides all other stacks attributes
-			if workflowStack != "" {
-				finalStack = workflowStack
+		}
+
+		recordRun()
+	}
+
+	for {
+		// Start the steps that have all their dependencies completed. Don't start new steps after a step has failed
+		for index, step := range steps {
+			if stepErr != nil || running >= maxParallel {
+				break
 			}
-			if stepStack != "" {
-				finalStack = stepStack
+
+			if results[index].status != workflowStepStatusPending || !workflowStepNeedsCompleted(step, stepIndexes, results) {
+				continue
 			}
-			if commandLineStack != "" {
-				finalStack = commandLineStack
+
+			renderedStep := step
+			if templatesEnabled {
+				renderedStep, err = renderWorkflowStep(workflow, step, templateData)
+				if err != nil {
+					completeStep(workflowStepResult{index: index, status: workflowStepStatusFailed, err: err})
+					break
+				}
 			}
 
-			if finalStack != "" {
-				args = append(args, []string{"-s", finalStack}...)
-				u.PrintInfo(fmt.Sprintf("Stack: %s", finalStack))
+			results[index].status = workflowStepStatusRunning
+			run.Steps[index].Status = workflowStepStatusRunning
+			running++
+
+			go func(index int, step cfg.WorkflowStep) {
+				start := time.Now()
+				attempts, stdout, err := executeWorkflowStepWithRetries(workflow, workflowDefinition, step, index, stepSettings[index], dryRun, commandLineStack, prefixOutput, templatesEnabled)
+
+				var outputs map[string]any
+				if err == nil {
+					outputs, err = parseWorkflowStepOutputs(step, stdout, dryRun)
+				}
+
+				result := workflowStepResult{
+					index:     index,
+					status:    workflowStepStatusSucceeded,
+					attempts:  attempts,
+					startedAt: start,
+					duration:  time.Since(start),
+					stdout:    strings.TrimSpace(stdout),
+					outputs:   outputs,
+					err:       err,
+				}
+
+				if err != nil {
+					result.status = workflowStepStatusFailed
+					if stepSettings[index].continueOnError {
+						result.status = workflowStepStatusContinued
+					}
+				}
+
+				done <- result
+			}(index, renderedStep)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-done
+		running--
+		completeStep(result)
+	}
+
+	for index := range results {
+		if results[index].status == workflowStepStatusPending {
+			results[index].status = workflowStepStatusCanceled
+			run.Steps[index].Status = workflowStepStatusCanceled
+		}
+	}
+
+	run.Status = workflowRunStatusSucceeded
+	if stepErr != nil {
+		run.Status = workflowRunStatusFailed
+	}
+	run.CompletedAt = formatWorkflowTime(time.Now())
+	recordRun()
+
+	if err = printWorkflowSummary(steps, results); err != nil {
+		return err
+	}
+
+	return stepErr
+}
+
+// setDefaultWorkflowStepNames checks if the steps have the `name` attribute.
+// If not, generate a friendly name consisting of a prefix of `step` and followed by the index of the
+// step (the index starts with 1, so the first generated step name would be `step1`)
+func setDefaultWorkflowStepNames(steps []cfg.WorkflowStep) {
+	for index, step := range steps {
+		if step.Name == "" {
+			// When iterating through a slice with a range loop, if elements need to be changed,
+			// changing the returned value from the range is not changing the original slice element.
+			// That return value is a copy of the element.
+			// So doing changes to it will not affect the original elements.
+			// We need to access the element with the index returned from the range iterator and change it there.
+			// https://medium.com/@nsspathirana/common-mistakes-with-go-slices-95f2e9b362a9
+			steps[index].Name = fmt.Sprintf("step%d", index+1)
+		}
+	}
+}
+
+// validateWorkflowSteps checks that the step names are unique, the `needs` attributes refer to the existing steps,
+// and the dependencies between the steps don't have cycles. It returns the map of step names to their indexes
+func validateWorkflowSteps(workflow string, steps []cfg.WorkflowStep) (map[string]int, error) {
+	stepIndexes := map[string]int{}
+
+	for index, step := range steps {
+		if _, ok := stepIndexes[step.Name]; ok {
+			return nil, fmt.Errorf("workflow '%s' has more than one step with the name '%s'", workflow, step.Name)
+		}
+		stepIndexes[step.Name] = index
+	}
+
+	for _, step := range steps {
+		for _, need := range step.Needs {
+			if _, ok := stepIndexes[need]; !ok {
+				return nil, fmt.Errorf("the step '%s' in the workflow '%s' needs the step '%s' which is not defined in the workflow", step.Name, workflow, need)
 			}
+		}
+	}
+
+	// Detect cycles using depth-first search. A step is 'visiting' while its dependencies are being checked
+	const (
+		notVisited = iota
+		visiting
+		visited
+	)
 
-			if err = ExecuteShellCommand("atmos", args, ".", []string{}, dryRun, true, ""); err != nil {
+	states := make([]int, len(steps))
+
+	var visit func(index int, path []string) error
+	visit = func(index int, path []string) error {
+		path = append(path, steps[index].Name)
+
+		switch states[index] {
+		case visiting:
+			return fmt.Errorf("the steps in the workflow '%s' have a dependency cycle: %s", workflow, strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+
+		states[index] = visiting
+		for _, need := range steps[index].Needs {
+			if err := visit(stepIndexes[need], path); err != nil {
 				return err
 			}
-		} else {
-			return fmt.Errorf("invalid workflow step type '%s'. Supported types are 'atmos' and 'shell'", commandType)
 		}
+		states[index] = visited
+
+		return nil
+	}
+
+	for index := range steps {
+		if err := visit(index, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return stepIndexes, nil
+}
+
+// workflowStepNeedsCompleted checks if all the steps from the `needs` attribute of the step have succeeded,
+// failed with `continue_on_error` enabled, were skipped by `--from-step`, or succeeded in the resumed run
+func workflowStepNeedsCompleted(step cfg.WorkflowStep, stepIndexes map[string]int, results []workflowStepResult) bool {
+	for _, need := range step.Needs {
+		status := results[stepIndexes[need]].status
+		if status != workflowStepStatusSucceeded && status != workflowStepStatusContinued &&
+			status != workflowStepStatusSkipped && status != workflowStepStatusResumed {
+			return false
+		}
+	}
+	return true
+}
+
+// workflowStepsHaveNeeds returns true if any of the workflow steps declares the `needs` attribute
+func workflowStepsHaveNeeds(steps []cfg.WorkflowStep) bool {
+	for _, step := range steps {
+		if len(step.Needs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// executeWorkflowStepWithRetries executes the workflow step. If the step fails or times out, it's retried according to the `retry` settings.
+// If `prefixOutput` is true (the step can run in parallel with other steps, or the steps declare `needs`), each line of the step output
+// is prefixed with the step name.
+// The stdout of the step is captured only if the step declares `outputs`.
+// Otherwise, the step writes directly to the terminal, so interactive prompts, TTY detection and partial lines work as expected.
+// It returns the number of attempts and the captured stdout of the last attempt
+func executeWorkflowStepWithRetries(
+	workflow string,
+	workflowDefinition *cfg.WorkflowDefinition,
+	step cfg.WorkflowStep,
+	stepIdx int,
+	settings workflowStepSettings,
+	dryRun bool,
+	commandLineStack string,
+	prefixOutput bool,
+	templatesEnabled bool,
+) (int, string, error) {
+	var out io.Writer = os.Stdout
+	var errOut io.Writer = os.Stderr
+
+	if prefixOutput {
+		prefix := fmt.Sprintf("[%s] ", step.Name)
+		prefixedOut := newWorkflowStepOutputWriter(prefix, os.Stdout)
+		prefixedErrOut := newWorkflowStepOutputWriter(prefix, os.Stderr)
+
+		defer prefixedOut.Flush()
+		defer prefixedErrOut.Flush()
+
+		out = prefixedOut
+		errOut = prefixedErrOut
+	}
+
+	// The stdout of the command is captured to get the step outputs
+	var stdout *bytes.Buffer
+	if len(step.Outputs) > 0 {
+		stdout = &bytes.Buffer{}
+	}
+
+	var err error
+	attempt := 1
+
+	for ; ; attempt++ {
+		if stdout != nil {
+			stdout.Reset()
+		}
+		err = executeWorkflowStepWithTimeout(workflow, workflowDefinition, step, stepIdx, settings.timeout, dryRun, commandLineStack, templatesEnabled, out, errOut, stdout)
+		if err == nil || attempt >= settings.maxAttempts {
+			break
+		}
+
+		delay := getWorkflowStepRetryDelay(settings, attempt)
+		fmt.Fprintf(errOut, "Attempt %d of %d failed: %v\n", attempt, settings.maxAttempts, err)
+		fmt.Fprintf(out, "Retrying in %s\n", delay)
+		time.Sleep(delay)
+	}
+
+	if err != nil && settings.continueOnError {
+		fmt.Fprintf(errOut, "The step failed, continuing the workflow since 'continue_on_error' is enabled: %v\n", err)
+	}
+
+	if stdout == nil {
+		return attempt, "", err
+	}
+	return attempt, stdout.String(), err
+}
+
+// executeWorkflowStepWithTimeout executes the workflow step and stops it if it does not complete in the provided time (if the timeout is not zero)
+func executeWorkflowStepWithTimeout(
+	workflow string,
+	workflowDefinition *cfg.WorkflowDefinition,
+	step cfg.WorkflowStep,
+	stepIdx int,
+	timeout time.Duration,
+	dryRun bool,
+	commandLineStack string,
+	templatesEnabled bool,
+	out io.Writer,
+	errOut io.Writer,
+	stdout *bytes.Buffer,
+) error {
+	ctx := context.Background()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := executeWorkflowStep(ctx, workflow, workflowDefinition, step, stepIdx, dryRun, commandLineStack, templatesEnabled, out, errOut, stdout)
+
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+
+	return err
+}
+
+// getWorkflowStepRetryDelay returns the delay before the next attempt.
+// With the `exponential` backoff, the delay is doubled after each attempt (up to `max_delay` if specified)
+func getWorkflowStepRetryDelay(settings workflowStepSettings, attempt int) time.Duration {
+	delay := settings.delay
+
+	if settings.backoff == workflowStepRetryBackoffExponential {
+		for i := 1; i < attempt; i++ {
+			delay *= 2
+			if settings.maxDelay > 0 && delay >= settings.maxDelay {
+				break
+			}
+		}
+	}
+
+	if settings.maxDelay > 0 && delay > settings.maxDelay {
+		delay = settings.maxDelay
+	}
 
-		fmt.Println()
+	return delay
+}
+
+// getWorkflowStepSettings merges the workflow-level `retry`, `timeout` and `continue_on_error` defaults with the step-level settings
+func getWorkflowStepSettings(workflow string, workflowDefinition *cfg.WorkflowDefinition, step cfg.WorkflowStep) (workflowStepSettings, error) {
+	settings := workflowStepSettings{
+		maxAttempts:     1,
+		backoff:         workflowStepRetryBackoffConstant,
+		continueOnError: workflowDefinition.ContinueOnError,
 	}
 
+	if step.ContinueOnError != nil {
+		settings.continueOnError = *step.ContinueOnError
+	}
+
+	timeout := workflowDefinition.Timeout
+	if step.Timeout != "" {
+		timeout = step.Timeout
+	}
+
+	var err error
+
+	if timeout != "" {
+		if settings.timeout, err = time.ParseDuration(timeout); err != nil {
+			return settings, fmt.Errorf("invalid 'timeout: %s' in the step '%s' of the workflow '%s': %w", timeout, step.Name, workflow, err)
+		}
+	}
+
+	retry := workflowDefinition.Retry
+	if step.Retry != nil {
+		retry = step.Retry
+	}
+
+	if retry == nil {
+		return settings, nil
+	}
+
+	if retry.MaxAttempts > 1 {
+		settings.maxAttempts = retry.MaxAttempts
+	}
+
+	if retry.Delay != "" {
+		if settings.delay, err = time.ParseDuration(retry.Delay); err != nil {
+			return settings, fmt.Errorf("invalid 'retry.delay: %s' in the step '%s' of the workflow '%s': %w", retry.Delay, step.Name, workflow, err)
+		}
+	}
+
+	if retry.MaxDelay != "" {
+		if settings.maxDelay, err = time.ParseDuration(retry.MaxDelay); err != nil {
+			return settings, fmt.Errorf("invalid 'retry.max_delay: %s' in the step '%s' of the workflow '%s': %w", retry.MaxDelay, step.Name, workflow, err)
+		}
+	}
+
+	if retry.Backoff != "" {
+		if retry.Backoff != workflowStepRetryBackoffConstant && retry.Backoff != workflowStepRetryBackoffExponential {
+			return settings, fmt.Errorf("invalid 'retry.backoff: %s' in the step '%s' of the workflow '%s'. Supported backoffs are '%s' and '%s'",
+				retry.Backoff,
+				step.Name,
+				workflow,
+				workflowStepRetryBackoffConstant,
+				workflowStepRetryBackoffExponential,
+			)
+		}
+		settings.backoff = retry.Backoff
+	}
+
+	return settings, nil
+}
+
+// executeWorkflowStep executes the `atmos` or `shell` command of the workflow step.
+// The output of the command is written to `out` and `errOut`, and the stdout of the command is also written to `stdout` (if not nil)
+func executeWorkflowStep(
+	ctx context.Context,
+	workflow string,
+	workflowDefinition *cfg.WorkflowDefinition,
+	step cfg.WorkflowStep,
+	stepIdx int,
+	dryRun bool,
+	commandLineStack string,
+	templatesEnabled bool,
+	out io.Writer,
+	errOut io.Writer,
+	stdout *bytes.Buffer,
+) error {
+	var command = strings.TrimSpace(step.Command)
+	var commandType = strings.TrimSpace(step.Type)
+
+	// Write the command output directly to `out` if it's not captured, so the command is attached to the terminal
+	commandOut := out
+	if stdout != nil {
+		commandOut = io.MultiWriter(out, stdout)
+	}
+
+	fmt.Fprintf(out, "Executing workflow step: %s\n", command)
+
+	if commandType == "" {
+		commandType = "atmos"
+	}
+
+	if commandType == "shell" {
+		commandName := fmt.Sprintf("%s-step-%d", workflow, stepIdx)
+		return executeShellWithOutput(ctx, command, commandName, ".", []string{}, dryRun, commandOut, errOut)
+	}
+
+	if commandType != "atmos" {
+		return fmt.Errorf("invalid workflow step type '%s'. Supported types are 'atmos' and 'shell'", commandType)
+	}
+
+	// The commands are split on whitespace. In the workflows that use templates, the quoted arguments are supported,
+	// so the rendered values with spaces can be passed as one argument
+	args := strings.Fields(command)
+	if templatesEnabled {
+		var err error
+		if args, err = splitWorkflowStepCommand(command); err != nil {
+			return err
+		}
+	}
+
+	finalStack := getWorkflowStepStack(workflowDefinition, step, commandLineStack)
+
+	if finalStack != "" {
+		args = append(args, []string{"-s", finalStack}...)
+		fmt.Fprintf(out, "Stack: %s\n", finalStack)
+	}
+
+	fmt.Fprintf(out, "Executing command: atmos %s\n", strings.Join(args, " "))
+
+	return executeShellCommandWithOutput(ctx, "atmos", args, ".", []string{}, dryRun, commandOut, errOut)
+}
+
+// splitWorkflowStepCommand splits the command into the arguments the same way as a shell would do it, but only removes the quotes.
+// The variables (e.g. `$VAR`), globs, `~` and braces are not expanded, they are passed to `atmos` as they are written in the command
+func splitWorkflowStepCommand(command string) ([]string, error) {
+	var args []string
+	printer := syntax.NewPrinter()
+
+	err := syntax.NewParser().Words(strings.NewReader(command), func(word *syntax.Word) bool {
+		var arg strings.Builder
+		writeWorkflowStepWordParts(&arg, printer, word.Parts)
+		args = append(args, arg.String())
+		return true
+	})
+
+	return args, err
+}
+
+// writeWorkflowStepWordParts writes the parts of the shell word without the quotes. The other parts are written as they are in the command
+func writeWorkflowStepWordParts(arg *strings.Builder, printer *syntax.Printer, parts []syntax.WordPart) {
+	for _, part := range parts {
+		switch p := part.(type) {
+		case *syntax.SglQuoted:
+			arg.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			writeWorkflowStepWordParts(arg, printer, p.Parts)
+		default:
+			_ = printer.Print(arg, part)
+		}
+	}
+}
+
+// getWorkflowStepStack returns the stack for the workflow step of type `atmos`.
+// The workflow `stack` attribute overrides the stack in the `command` (if specified)
+// The step `stack` attribute overrides the stack in the `command` and the workflow `stack` attribute
+// The stack defined on the command line (`atmos workflow <name> -f <file> -s <stack>`) has the highest priority,
+// it overrides all other stacks attributes
+func getWorkflowStepStack(workflowDefinition *cfg.WorkflowDefinition, step cfg.WorkflowStep, commandLineStack string) string {
+	var workflowStack = strings.TrimSpace(workflowDefinition.Stack)
+	var stepStack = strings.TrimSpace(step.Stack)
+	var finalStack = ""
+
+	if workflowStack != "" {
+		finalStack = workflowStack
+	}
+	if stepStack != "" {
+		finalStack = stepStack
+	}
+	if commandLineStack != "" {
+		finalStack = commandLineStack
+	}
+
+	return finalStack
+}
+
+// processWorkflowInputs checks the inputs provided on the command line against the inputs declared in the workflow,
+// converts them to the declared types, and sets the default values for the inputs that are not provided
+func processWorkflowInputs(workflow string, workflowDefinition *cfg.WorkflowDefinition, inputs map[string]string) (map[string]any, error) {
+	result := map[string]any{}
+
+	for name := range inputs {
+		if _, ok := workflowDefinition.Inputs[name]; !ok {
+			return nil, fmt.Errorf("invalid '--input %s'. Workflow '%s' does not have the input '%s' defined", name, workflow, name)
+		}
+	}
+
+	for name, input := range workflowDefinition.Inputs {
+		value, ok := inputs[name]
+
+		if !ok && input.Default != nil {
+			value = fmt.Sprintf("%v", input.Default)
+			ok = true
+		}
+
+		if !ok && input.Required {
+			return nil, fmt.Errorf("the input '%s' is required by the workflow '%s'. Use '--input %s=<value>' to provide it", name, workflow, name)
+		}
+
+		converted, err := convertWorkflowInput(input.Type, value, ok)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s' of the input '%s' in the workflow '%s': %w", value, name, workflow, err)
+		}
+
+		result[name] = converted
+	}
+
+	return result, nil
+}
+
+// convertWorkflowInput converts the input value to the declared type (`string`, `number` or `boolean`).
+// If the value is not set, the zero value of the type is returned
+func convertWorkflowInput(inputType string, value string, isSet bool) (any, error) {
+	switch inputType {
+	case "", "string":
+		return value, nil
+	case "number":
+		if !isSet {
+			return 0, nil
+		}
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i, nil
+		}
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		if !isSet {
+			return false, nil
+		}
+		return strconv.ParseBool(value)
+	default:
+		return nil, fmt.Errorf("unsupported input type '%s'. Supported types are 'string', 'number' and 'boolean'", inputType)
+	}
+}
+
+// workflowUsesTemplates checks if the workflow declares `inputs` or any of its steps declares `outputs`
+func workflowUsesTemplates(workflowDefinition *cfg.WorkflowDefinition, steps []cfg.WorkflowStep) bool {
+	if len(workflowDefinition.Inputs) > 0 {
+		return true
+	}
+
+	for _, step := range steps {
+		if len(step.Outputs) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderWorkflowStep processes the Go templates in the step `command` and `stack` attributes
+func renderWorkflowStep(workflow string, step cfg.WorkflowStep, templateData map[string]any) (cfg.WorkflowStep, error) {
+	var err error
+	tmplName := fmt.Sprintf("%s-%s", workflow, step.Name)
+
+	if step.Command, err = u.ProcessTmpl(tmplName, step.Command, templateData); err != nil {
+		return step, err
+	}
+
+	if step.Stack, err = u.ProcessTmpl(tmplName, step.Stack, templateData); err != nil {
+		return step, err
+	}
+
+	return step, nil
+}
+
+// parseWorkflowStepOutputs finds the outputs declared in the step `outputs` attribute in the step stdout.
+// Each output must be written to stdout as a line in the format `<name>=<value>`. If an output is written more than once, the last value is used
+func parseWorkflowStepOutputs(step cfg.WorkflowStep, stdout string, dryRun bool) (map[string]any, error) {
+	outputs := map[string]any{}
+
+	for _, line := range strings.Split(stdout, "\n") {
+		name, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if found && u.SliceContainsString(step.Outputs, name) {
+			outputs[name] = value
+		}
+	}
+
+	for _, name := range step.Outputs {
+		if _, ok := outputs[name]; ok {
+			continue
+		}
+		// The commands are not executed in dry run mode, so the outputs are empty
+		if dryRun {
+			outputs[name] = ""
+			continue
+		}
+		return nil, fmt.Errorf("the step '%s' did not write the output '%s' to stdout. Expected a line in the format '%s=<value>'", step.Name, name, name)
+	}
+
+	return outputs, nil
+}
+
+// printWorkflowSummary prints the table with the status and the duration of each workflow step
+func printWorkflowSummary(steps []cfg.WorkflowStep, results []workflowStepResult) error {
+	fmt.Println()
+	u.PrintInfo("Workflow summary:")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "STEP\tSTATUS\tATTEMPTS\tDURATION")
+
+	for index, step := range steps {
+		attempts := "-"
+		duration := "-"
+		if results[index].attempts > 0 {
+			attempts = strconv.Itoa(results[index].attempts)
+			duration = results[index].duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", step.Name, results[index].status, attempts, duration)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
 	return nil
 }
+
+// workflowStepOutputWriter prefixes each line written to it with the step name.
+// Complete lines are written to the underlying writer under the shared lock, so the lines from the steps executed in parallel don't interleave
+type workflowStepOutputWriter struct {
+	prefix string
+	out    io.Writer
+	buf    bytes.Buffer
+}
+
+func newWorkflowStepOutputWriter(prefix string, out io.Writer) *workflowStepOutputWriter {
+	return &workflowStepOutputWriter{prefix: prefix, out: out}
+}
+
+// Write buffers the data and writes all the complete lines to the underlying writer
+func (w *workflowStepOutputWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	for {
+		line, err := w.buf.ReadBytes('\n')
+		if err != nil {
+			// Keep the incomplete line in the buffer until the rest of it is written
+			w.buf.Write(line)
+			break
+		}
+		if err = w.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the remaining incomplete line (if any) to the underlying writer
+func (w *workflowStepOutputWriter) Flush() {
+	if w.buf.Len() > 0 {
+		line := append(w.buf.Bytes(), '\n')
+		w.buf.Reset()
+		_ = w.writeLine(line)
+	}
+}
+
+func (w *workflowStepOutputWriter) writeLine(line []byte) error {
+	workflowOutputLock.Lock()
+	defer workflowOutputLock.Unlock()
+
+	_, err := w.out.Write(append([]byte(w.prefix), line...))
+	return err
+}
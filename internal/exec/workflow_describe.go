@@ -0,0 +1,127 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// WorkflowDescription describes a workflow with the resolved steps
+type WorkflowDescription struct {
+	Workflow    string                       `yaml:"workflow" json:"workflow"`
+	File        string                       `yaml:"file" json:"file"`
+	Description string                       `yaml:"description,omitempty" json:"description,omitempty"`
+	Stack       string                       `yaml:"stack,omitempty" json:"stack,omitempty"`
+	MaxParallel int                          `yaml:"max_parallel" json:"max_parallel"`
+	Inputs      map[string]cfg.WorkflowInput `yaml:"inputs,omitempty" json:"inputs,omitempty"`
+	Steps       []cfg.WorkflowStep           `yaml:"steps" json:"steps"`
+}
+
+// ExecuteWorkflowDescribeCmd executes `workflow describe` command
+func ExecuteWorkflowDescribeCmd(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("invalid arguments. The command requires one argument `workflow name`")
+	}
+
+	info, err := processCommandLineArgs("", cmd, args)
+	if err != nil {
+		return err
+	}
+
+	cliConfig, err := cfg.InitCliConfig(info, true)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+
+	workflowFile, err := flags.GetString("file")
+	if err != nil {
+		return err
+	}
+
+	commandLineStack, err := flags.GetString("stack")
+	if err != nil {
+		return err
+	}
+
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+
+	if format == "" {
+		format = "yaml"
+	}
+
+	if format != "yaml" && format != "json" {
+		return fmt.Errorf("invalid '--format' flag '%s'. Valid values are 'yaml' (default) and 'json'", format)
+	}
+
+	workflow := args[0]
+
+	workflowPath, workflowDefinition, err := FindWorkflow(cliConfig, workflow, workflowFile)
+	if err != nil {
+		return err
+	}
+
+	description, err := describeWorkflow(cliConfig, workflow, workflowPath, workflowDefinition, commandLineStack)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		return u.PrintAsJSON(description)
+	}
+
+	return u.PrintAsYAML(description)
+}
+
+// describeWorkflow resolves the names, types and stacks of the workflow steps in the same way as `ExecuteWorkflow` does
+func describeWorkflow(
+	cliConfig cfg.CliConfiguration,
+	workflow string,
+	workflowPath string,
+	workflowDefinition cfg.WorkflowDefinition,
+	commandLineStack string,
+) (WorkflowDescription, error) {
+	// Copy the steps to not modify the workflow definition
+	steps := make([]cfg.WorkflowStep, len(workflowDefinition.Steps))
+	copy(steps, workflowDefinition.Steps)
+
+	setDefaultWorkflowStepNames(steps)
+
+	if _, err := validateWorkflowSteps(workflow, steps); err != nil {
+		return WorkflowDescription{}, err
+	}
+
+	for i := range steps {
+		if steps[i].Type == "" {
+			steps[i].Type = "atmos"
+		}
+		if steps[i].Type == "atmos" {
+			steps[i].Stack = getWorkflowStepStack(&workflowDefinition, steps[i], commandLineStack)
+		}
+		steps[i].Command = strings.TrimSpace(steps[i].Command)
+	}
+
+	maxParallel := workflowDefinition.MaxParallel
+	if maxParallel <= 0 {
+		maxParallel = 1
+	}
+
+	return WorkflowDescription{
+		Workflow:    workflow,
+		File:        getWorkflowRunFile(cliConfig, workflowPath),
+		Description: strings.TrimSpace(workflowDefinition.Description),
+		Stack:       workflowDefinition.Stack,
+		MaxParallel: maxParallel,
+		Inputs:      workflowDefinition.Inputs,
+		Steps:       steps,
+	}, nil
+}
@@ -0,0 +1,271 @@
+package exec
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// WorkflowListItem describes a workflow defined in one of the workflow files
+type WorkflowListItem struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	File        string `yaml:"file" json:"file"`
+	Steps       int    `yaml:"steps" json:"steps"`
+	Stack       string `yaml:"stack,omitempty" json:"stack,omitempty"`
+}
+
+// ExecuteWorkflowListCmd executes `workflow list` command
+func ExecuteWorkflowListCmd(cmd *cobra.Command, args []string) error {
+	info, err := processCommandLineArgs("", cmd, args)
+	if err != nil {
+		return err
+	}
+
+	cliConfig, err := cfg.InitCliConfig(info, true)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+
+	workflowFile, err := flags.GetString("file")
+	if err != nil {
+		return err
+	}
+
+	format, err := flags.GetString("format")
+	if err != nil {
+		return err
+	}
+
+	if format == "" {
+		format = "table"
+	}
+
+	if format != "table" && format != "yaml" && format != "json" {
+		return fmt.Errorf("invalid '--format' flag '%s'. Valid values are 'table' (default), 'yaml' and 'json'", format)
+	}
+
+	workflowFiles, err := FindWorkflowFiles(cliConfig)
+	if err != nil {
+		return err
+	}
+
+	var items []WorkflowListItem
+
+	for _, f := range workflowFiles {
+		if workflowFile != "" && !workflowFileMatches(f, workflowFile) {
+			continue
+		}
+
+		workflowConfig, err := ReadWorkflowFile(path.Join(getWorkflowsBasePath(cliConfig), f))
+		if err != nil {
+			// Skip the YAML files that are not workflow files
+			u.PrintMessageVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Skipping the file '%s': %v", f, err))
+			continue
+		}
+
+		names := lo.Keys(workflowConfig)
+		sort.Strings(names)
+
+		for _, name := range names {
+			workflowDefinition := workflowConfig[name]
+			items = append(items, WorkflowListItem{
+				Name:        name,
+				Description: strings.TrimSpace(workflowDefinition.Description),
+				File:        f,
+				Steps:       len(workflowDefinition.Steps),
+				Stack:       workflowDefinition.Stack,
+			})
+		}
+	}
+
+	switch format {
+	case "yaml":
+		return u.PrintAsYAML(items)
+	case "json":
+		return u.PrintAsJSON(items)
+	}
+
+	if len(items) == 0 {
+		u.PrintInfo("No workflows found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "WORKFLOW\tFILE\tSTEPS\tSTACK\tDESCRIPTION")
+
+	for _, item := range items {
+		stack := item.Stack
+		if stack == "" {
+			stack = "-"
+		}
+		// Show only the first line of multi-line descriptions
+		description, _, _ := strings.Cut(item.Description, "\n")
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", item.Name, item.File, item.Steps, stack, description)
+	}
+
+	return w.Flush()
+}
+
+// getWorkflowsBasePath returns the path to the folder with the workflow files
+func getWorkflowsBasePath(cliConfig cfg.CliConfiguration) string {
+	return path.Join(cliConfig.BasePath, cliConfig.Workflows.BasePath)
+}
+
+// FindWorkflowFiles returns the sorted list of all the workflow files (relative to the workflows base path).
+// The hidden files and folders are skipped
+func FindWorkflowFiles(cliConfig cfg.CliConfiguration) ([]string, error) {
+	workflowsBasePath := getWorkflowsBasePath(cliConfig)
+
+	if !u.FileOrDirExists(workflowsBasePath) {
+		return nil, fmt.Errorf("the workflows folder '%s' does not exist. Check 'workflows.base_path' in 'atmos.yaml'", workflowsBasePath)
+	}
+
+	var files []string
+
+	err := filepath.WalkDir(workflowsBasePath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(d.Name(), ".") && p != workflowsBasePath {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !u.IsYaml(p) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(workflowsBasePath, p)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ReadWorkflowFile reads the workflow file and returns the workflows defined in it
+func ReadWorkflowFile(workflowPath string) (cfg.WorkflowConfig, error) {
+	if !u.FileExists(workflowPath) {
+		return nil, fmt.Errorf("file '%s' does not exist", workflowPath)
+	}
+
+	fileContent, err := os.ReadFile(workflowPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var yamlContent cfg.WorkflowFile
+
+	if err = yaml.Unmarshal(fileContent, &yamlContent); err != nil {
+		return nil, err
+	}
+
+	workflowConfig, ok := yamlContent["workflows"]
+	if !ok {
+		return nil, fmt.Errorf("a workflow file must be a map with top-level 'workflows:' key. Invalid file '%s'", workflowPath)
+	}
+
+	return workflowConfig, nil
+}
+
+// FindWorkflow finds the workflow definition and returns the path to the workflow file and the workflow definition.
+// If the workflow file is not specified, the workflow is searched in all the workflow files, and the workflow name must be unique across the files
+func FindWorkflow(cliConfig cfg.CliConfiguration, workflow string, workflowFile string) (string, cfg.WorkflowDefinition, error) {
+	if workflowFile != "" {
+		var workflowPath string
+		if u.IsPathAbsolute(workflowFile) {
+			workflowPath = workflowFile
+		} else {
+			workflowPath = path.Join(getWorkflowsBasePath(cliConfig), workflowFile)
+		}
+
+		// If the file is specified without an extension, use the default extension
+		if filepath.Ext(workflowPath) == "" {
+			workflowPath = workflowPath + cfg.DefaultStackConfigFileExtension
+		}
+
+		workflowConfig, err := ReadWorkflowFile(workflowPath)
+		if err != nil {
+			return "", cfg.WorkflowDefinition{}, err
+		}
+
+		workflowDefinition, ok := workflowConfig[workflow]
+		if !ok {
+			return "", cfg.WorkflowDefinition{}, fmt.Errorf("the file '%s' does not have the '%s' workflow defined", workflowPath, workflow)
+		}
+
+		return workflowPath, workflowDefinition, nil
+	}
+
+	workflowFiles, err := FindWorkflowFiles(cliConfig)
+	if err != nil {
+		return "", cfg.WorkflowDefinition{}, err
+	}
+
+	var foundFiles []string
+	var workflowDefinition cfg.WorkflowDefinition
+
+	for _, f := range workflowFiles {
+		workflowConfig, err := ReadWorkflowFile(path.Join(getWorkflowsBasePath(cliConfig), f))
+		if err != nil {
+			// Skip the YAML files that are not workflow files
+			u.PrintMessageVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Skipping the file '%s': %v", f, err))
+			continue
+		}
+
+		if d, ok := workflowConfig[workflow]; ok {
+			foundFiles = append(foundFiles, f)
+			workflowDefinition = d
+		}
+	}
+
+	if len(foundFiles) == 0 {
+		return "", cfg.WorkflowDefinition{}, fmt.Errorf("the workflow '%s' is not defined in any of the workflow files in '%s'",
+			workflow,
+			getWorkflowsBasePath(cliConfig),
+		)
+	}
+
+	if len(foundFiles) > 1 {
+		return "", cfg.WorkflowDefinition{}, fmt.Errorf("the workflow '%s' is defined in more than one file: %s. Use the '--file' flag to specify the file",
+			workflow,
+			strings.Join(foundFiles, ", "),
+		)
+	}
+
+	return path.Join(getWorkflowsBasePath(cliConfig), foundFiles[0]), workflowDefinition, nil
+}
+
+// workflowFileMatches checks if the workflow file (relative to the workflows base path) matches the file provided in the `--file` flag.
+// The file can be specified without the extension
+func workflowFileMatches(workflowFile string, file string) bool {
+	return workflowFile == file || strings.TrimSuffix(workflowFile, filepath.Ext(workflowFile)) == file
+}
@@ -0,0 +1,189 @@
+package exec
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v2"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+const (
+	// Maximum number of the workflow runs kept in the state file
+	maxWorkflowStateRuns = 100
+
+	workflowRunStatusRunning   = "running"
+	workflowRunStatusSucceeded = "succeeded"
+	workflowRunStatusFailed    = "failed"
+)
+
+// getWorkflowStateFilePath returns the path to the workflow state file.
+// If `workflows.state.base_path` is not configured, the state file is kept in the workflows base path
+func getWorkflowStateFilePath(cliConfig cfg.CliConfiguration) string {
+	stateDir := cliConfig.Workflows.State.BasePath
+
+	if stateDir == "" {
+		stateDir = getWorkflowsBasePath(cliConfig)
+	}
+
+	return path.Join(stateDir, cfg.WorkflowStateFileName)
+}
+
+// getWorkflowRunFile returns the path to the workflow file relative to the workflows base path (to identify the runs of the workflow)
+func getWorkflowRunFile(cliConfig cfg.CliConfiguration, workflowPath string) string {
+	relPath, err := filepath.Rel(getWorkflowsBasePath(cliConfig), workflowPath)
+	if err != nil {
+		return workflowPath
+	}
+
+	return filepath.ToSlash(relPath)
+}
+
+// ReadWorkflowStateFile reads the workflow state file. If the file does not exist, an empty state is returned
+func ReadWorkflowStateFile(stateFile string) (cfg.WorkflowState, error) {
+	var state cfg.WorkflowState
+
+	if !u.FileExists(stateFile) {
+		return state, nil
+	}
+
+	stateFileContent, err := os.ReadFile(stateFile)
+	if err != nil {
+		return state, err
+	}
+
+	if err = yaml.Unmarshal(stateFileContent, &state); err != nil {
+		return state, fmt.Errorf("invalid workflow state file '%s': %w", stateFile, err)
+	}
+
+	return state, nil
+}
+
+// saveWorkflowRun adds the run to the workflow state file, or updates it if the run is already in the file.
+// The state file is locked while it's re-read and written, so the runs recorded concurrently by other `atmos` processes are preserved.
+// Only the most recent runs are kept in the file
+func saveWorkflowRun(stateFile string, run cfg.WorkflowRun) (err error) {
+	if err = os.MkdirAll(filepath.Dir(stateFile), 0755); err != nil {
+		return err
+	}
+
+	unlock, err := u.LockFile(stateFile + ".lock")
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	state, err := ReadWorkflowStateFile(stateFile)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range state.Runs {
+		if state.Runs[i].Id == run.Id {
+			state.Runs[i] = run
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		state.Runs = append(state.Runs, run)
+	}
+
+	if len(state.Runs) > maxWorkflowStateRuns {
+		state.Runs = state.Runs[len(state.Runs)-maxWorkflowStateRuns:]
+	}
+
+	// Write to a temp file and rename it, so the state file is never read partially written
+	tempFile := stateFile + ".tmp"
+	if err = u.WriteToFileAsYAML(tempFile, state, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile, stateFile)
+}
+
+// newWorkflowRun creates a new workflow run record with all the steps pending
+func newWorkflowRun(
+	workflow string,
+	workflowFile string,
+	commandLineStack string,
+	inputs map[string]string,
+	steps []cfg.WorkflowStep,
+) cfg.WorkflowRun {
+	run := cfg.WorkflowRun{
+		Id:        uuid.New().String(),
+		Workflow:  workflow,
+		File:      workflowFile,
+		Stack:     commandLineStack,
+		Inputs:    inputs,
+		Status:    workflowRunStatusRunning,
+		StartedAt: formatWorkflowTime(time.Now()),
+	}
+
+	for _, step := range steps {
+		run.Steps = append(run.Steps, cfg.WorkflowRunStep{Name: step.Name, Status: workflowStepStatusPending})
+	}
+
+	return run
+}
+
+// findLastWorkflowRun returns the most recent run of the workflow from the same file, with the same stack and the same inputs
+func findLastWorkflowRun(
+	state cfg.WorkflowState,
+	workflow string,
+	workflowFile string,
+	commandLineStack string,
+	inputs map[string]string,
+) (cfg.WorkflowRun, bool) {
+	for i := len(state.Runs) - 1; i >= 0; i-- {
+		run := state.Runs[i]
+
+		if run.Workflow != workflow || run.File != workflowFile || run.Stack != commandLineStack {
+			continue
+		}
+
+		// Nil and empty maps are considered equal
+		if len(run.Inputs) == 0 && len(inputs) == 0 || reflect.DeepEqual(run.Inputs, inputs) {
+			return run, true
+		}
+	}
+
+	return cfg.WorkflowRun{}, false
+}
+
+// workflowRunStepSucceeded checks if the step succeeded in the run (or was resumed from a previous run where it succeeded)
+func workflowRunStepSucceeded(run cfg.WorkflowRun, stepName string) (cfg.WorkflowRunStep, bool) {
+	for _, step := range run.Steps {
+		if step.Name == stepName && (step.Status == workflowStepStatusSucceeded || step.Status == workflowStepStatusResumed) {
+			return step, true
+		}
+	}
+	return cfg.WorkflowRunStep{}, false
+}
+
+// stringifyWorkflowInputs converts the input values to strings to record them in the state file and compare them between the runs
+func stringifyWorkflowInputs(inputs map[string]any) map[string]string {
+	result := map[string]string{}
+	for k, v := range inputs {
+		result[k] = fmt.Sprintf("%v", v)
+	}
+	return result
+}
+
+func formatWorkflowTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,165 @@
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	u "github.com/cloudposse/atmos/pkg/utils"
+)
+
+// ExecuteWorkflowStatusCmd executes `workflow status` command
+func ExecuteWorkflowStatusCmd(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return errors.New("invalid arguments. The command accepts one optional argument `workflow name`")
+	}
+
+	info, err := processCommandLineArgs("", cmd, args)
+	if err != nil {
+		return err
+	}
+
+	cliConfig, err := cfg.InitCliConfig(info, true)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+
+	workflowFile, err := flags.GetString("file")
+	if err != nil {
+		return err
+	}
+
+	stack, err := flags.GetString("stack")
+	if err != nil {
+		return err
+	}
+
+	limit, err := flags.GetInt("limit")
+	if err != nil {
+		return err
+	}
+
+	workflow := ""
+	if len(args) == 1 {
+		workflow = args[0]
+	}
+
+	stateFile := getWorkflowStateFilePath(cliConfig)
+
+	state, err := ReadWorkflowStateFile(stateFile)
+	if err != nil {
+		return err
+	}
+
+	runs := filterWorkflowRuns(state.Runs, workflow, workflowFile, stack, limit)
+
+	if len(runs) == 0 {
+		u.PrintInfo("No workflow runs found")
+		return nil
+	}
+
+	return printWorkflowRuns(runs)
+}
+
+// filterWorkflowRuns returns the most recent runs (newest first) of the workflow from the file in the stack.
+// Empty filters match all the runs. The workflow file can be specified without the extension
+func filterWorkflowRuns(runs []cfg.WorkflowRun, workflow string, workflowFile string, stack string, limit int) []cfg.WorkflowRun {
+	var result []cfg.WorkflowRun
+
+	for i := len(runs) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+
+		run := runs[i]
+
+		if workflow != "" && run.Workflow != workflow {
+			continue
+		}
+		if workflowFile != "" && !workflowFileMatches(run.File, workflowFile) {
+			continue
+		}
+		if stack != "" && run.Stack != stack {
+			continue
+		}
+
+		result = append(result, run)
+	}
+
+	return result
+}
+
+// printWorkflowRuns prints the table with the workflow runs
+func printWorkflowRuns(runs []cfg.WorkflowRun) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintln(w, "RUN ID\tWORKFLOW\tFILE\tSTACK\tSTATUS\tSTARTED\tDURATION\tSTEPS")
+
+	for _, run := range runs {
+		stack := run.Stack
+		if stack == "" {
+			stack = "-"
+		}
+
+		duration := "-"
+		if run.CompletedAt != "" {
+			startedAt, err1 := time.Parse(time.RFC3339, run.StartedAt)
+			completedAt, err2 := time.Parse(time.RFC3339, run.CompletedAt)
+			if err1 == nil && err2 == nil {
+				duration = completedAt.Sub(startedAt).String()
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			run.Id,
+			run.Workflow,
+			run.File,
+			stack,
+			run.Status,
+			run.StartedAt,
+			duration,
+			summarizeWorkflowRunSteps(run),
+		)
+	}
+
+	return w.Flush()
+}
+
+// summarizeWorkflowRunSteps returns the number of the steps in each status, and the names of the failed steps
+func summarizeWorkflowRunSteps(run cfg.WorkflowRun) string {
+	var statuses []string
+	counts := map[string]int{}
+	var failedSteps []string
+
+	for _, step := range run.Steps {
+		if _, ok := counts[step.Status]; !ok {
+			statuses = append(statuses, step.Status)
+		}
+		counts[step.Status]++
+
+		if step.Status == workflowStepStatusFailed {
+			failedSteps = append(failedSteps, step.Name)
+		}
+	}
+
+	var parts []string
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[status], status))
+	}
+
+	summary := strings.Join(parts, ", ")
+
+	if len(failedSteps) > 0 {
+		summary = fmt.Sprintf("%s (failed: %s)", summary, strings.Join(failedSteps, ", "))
+	}
+
+	return summary
+}
@@ -65,6 +65,7 @@ func InitCliConfig(configAndStacksInfo ConfigAndStacksInfo, processStacks bool)
 
 	// Default configuration values
 	v.SetDefault("components.helmfile.use_eks", true)
+	v.SetDefault("vendor.max_concurrency", 4)
 
 	// Process config in system folder
 	configFilePath1 := ""
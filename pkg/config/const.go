@@ -36,5 +36,17 @@ const (
 
 	ComponentConfigFileName = "component.yaml"
 
+	AtmosVendorConfigFileName   = "vendor.yaml"
+	AtmosVendorLockFileName     = "vendor.lock.yaml"
+	AtmosVendorConfigKind       = "AtmosVendorConfig"
+	AtmosVendorLockKind         = "AtmosVendorLock"
+	AtmosVendorConfigApiVersion = "atmos/v1"
+
+	WorkflowStateFileName = ".workflow-state.yaml"
+
 	ImportSectionName = "import"
+
+	ListMergeStrategyReplace = "replace"
+	ListMergeStrategyAppend  = "append"
+	ListMergeStrategyMerge   = "merge"
 )
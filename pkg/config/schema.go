@@ -10,6 +10,8 @@ type CliConfiguration struct {
 	Commands                      []Command    `yaml:"commands" json:"commands" mapstructure:"commands"`
 	Integrations                  Integrations `yaml:"integrations" json:"integrations" mapstructure:"integrations"`
 	Schemas                       Schemas      `yaml:"schemas" json:"schemas" mapstructure:"schemas"`
+	Vendor                        Vendor       `yaml:"vendor" json:"vendor" mapstructure:"vendor"`
+	Settings                      CliSettings  `yaml:"settings" json:"settings" mapstructure:"settings"`
 	Initialized                   bool         `yaml:"initialized" json:"initialized" mapstructure:"initialized"`
 	StacksBaseAbsolutePath        string       `yaml:"stacksBaseAbsolutePath" json:"stacksBaseAbsolutePath"`
 	IncludeStackAbsolutePaths     []string     `yaml:"includeStackAbsolutePaths" json:"includeStackAbsolutePaths"`
@@ -43,16 +45,37 @@ type Components struct {
 }
 
 type Stacks struct {
-	BasePath      string   `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
-	IncludedPaths []string `yaml:"included_paths" json:"included_paths" mapstructure:"included_paths"`
-	ExcludedPaths []string `yaml:"excluded_paths" json:"excluded_paths" mapstructure:"excluded_paths"`
-	NamePattern   string   `yaml:"name_pattern" json:"name_pattern" mapstructure:"name_pattern"`
+	BasePath       string   `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
+	IncludedPaths  []string `yaml:"included_paths" json:"included_paths" mapstructure:"included_paths"`
+	ExcludedPaths  []string `yaml:"excluded_paths" json:"excluded_paths" mapstructure:"excluded_paths"`
+	NamePattern    string   `yaml:"name_pattern" json:"name_pattern" mapstructure:"name_pattern"`
+	MaxConcurrency int      `yaml:"max_concurrency" json:"max_concurrency" mapstructure:"max_concurrency"`
+}
+
+type WorkflowsState struct {
+	BasePath string `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
 }
 
 type Workflows struct {
+	BasePath string         `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
+	State    WorkflowsState `yaml:"state" json:"state" mapstructure:"state"`
+}
+
+type VendorCache struct {
+	Enabled  bool   `yaml:"enabled" json:"enabled" mapstructure:"enabled"`
 	BasePath string `yaml:"base_path" json:"base_path" mapstructure:"base_path"`
 }
 
+type Vendor struct {
+	MaxConcurrency int         `yaml:"max_concurrency" json:"max_concurrency" mapstructure:"max_concurrency"`
+	Cache          VendorCache `yaml:"cache" json:"cache" mapstructure:"cache"`
+}
+
+type CliSettings struct {
+	ListMergeStrategy          string            `yaml:"list_merge_strategy" json:"list_merge_strategy" mapstructure:"list_merge_strategy"`
+	ListMergeStrategyOverrides map[string]string `yaml:"list_merge_strategy_overrides" json:"list_merge_strategy_overrides" mapstructure:"list_merge_strategy_overrides"`
+}
+
 type Logs struct {
 	Verbose bool `yaml:"verbose" json:"verbose" mapstructure:"verbose"`
 	Colors  bool `yaml:"colors" json:"colors" mapstructure:"colors"`
@@ -151,20 +174,70 @@ type ConfigAndStacksInfo struct {
 // Workflows
 
 type WorkflowStep struct {
-	Name    string `yaml:"name" json:"name" mapstructure:"name"`
-	Command string `yaml:"command" json:"command" mapstructure:"command"`
-	Stack   string `yaml:"stack,omitempty" json:"stack,omitempty" mapstructure:"stack"`
-	Type    string `yaml:"type,omitempty" json:"type,omitempty" mapstructure:"type"`
+	Name            string             `yaml:"name" json:"name" mapstructure:"name"`
+	Command         string             `yaml:"command" json:"command" mapstructure:"command"`
+	Stack           string             `yaml:"stack,omitempty" json:"stack,omitempty" mapstructure:"stack"`
+	Type            string             `yaml:"type,omitempty" json:"type,omitempty" mapstructure:"type"`
+	Needs           []string           `yaml:"needs,omitempty" json:"needs,omitempty" mapstructure:"needs"`
+	Retry           *WorkflowStepRetry `yaml:"retry,omitempty" json:"retry,omitempty" mapstructure:"retry"`
+	Timeout         string             `yaml:"timeout,omitempty" json:"timeout,omitempty" mapstructure:"timeout"`
+	ContinueOnError *bool              `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty" mapstructure:"continue_on_error"`
+	Outputs         []string           `yaml:"outputs,omitempty" json:"outputs,omitempty" mapstructure:"outputs"`
+}
+
+type WorkflowInput struct {
+	Type        string `yaml:"type,omitempty" json:"type,omitempty" mapstructure:"type"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty" mapstructure:"description"`
+	Default     any    `yaml:"default,omitempty" json:"default,omitempty" mapstructure:"default"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty" mapstructure:"required"`
+}
+
+type WorkflowStepRetry struct {
+	MaxAttempts int    `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty" mapstructure:"max_attempts"`
+	Delay       string `yaml:"delay,omitempty" json:"delay,omitempty" mapstructure:"delay"`
+	Backoff     string `yaml:"backoff,omitempty" json:"backoff,omitempty" mapstructure:"backoff"`
+	MaxDelay    string `yaml:"max_delay,omitempty" json:"max_delay,omitempty" mapstructure:"max_delay"`
 }
 
 type WorkflowDefinition struct {
-	Description string         `yaml:"description,omitempty" json:"description,omitempty" mapstructure:"description"`
-	Steps       []WorkflowStep `yaml:"steps" json:"steps" mapstructure:"steps"`
-	Stack       string         `yaml:"stack,omitempty" json:"stack,omitempty" mapstructure:"stack"`
+	Description     string                   `yaml:"description,omitempty" json:"description,omitempty" mapstructure:"description"`
+	Inputs          map[string]WorkflowInput `yaml:"inputs,omitempty" json:"inputs,omitempty" mapstructure:"inputs"`
+	Steps           []WorkflowStep           `yaml:"steps" json:"steps" mapstructure:"steps"`
+	Stack           string                   `yaml:"stack,omitempty" json:"stack,omitempty" mapstructure:"stack"`
+	MaxParallel     int                      `yaml:"max_parallel,omitempty" json:"max_parallel,omitempty" mapstructure:"max_parallel"`
+	Retry           *WorkflowStepRetry       `yaml:"retry,omitempty" json:"retry,omitempty" mapstructure:"retry"`
+	Timeout         string                   `yaml:"timeout,omitempty" json:"timeout,omitempty" mapstructure:"timeout"`
+	ContinueOnError bool                     `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty" mapstructure:"continue_on_error"`
 }
 
 type WorkflowConfig map[string]WorkflowDefinition
 
+type WorkflowRunStep struct {
+	Name        string         `yaml:"name" json:"name" mapstructure:"name"`
+	Status      string         `yaml:"status" json:"status" mapstructure:"status"`
+	Attempts    int            `yaml:"attempts,omitempty" json:"attempts,omitempty" mapstructure:"attempts"`
+	StartedAt   string         `yaml:"started_at,omitempty" json:"started_at,omitempty" mapstructure:"started_at"`
+	CompletedAt string         `yaml:"completed_at,omitempty" json:"completed_at,omitempty" mapstructure:"completed_at"`
+	Outputs     map[string]any `yaml:"outputs,omitempty" json:"outputs,omitempty" mapstructure:"outputs"`
+	Error       string         `yaml:"error,omitempty" json:"error,omitempty" mapstructure:"error"`
+}
+
+type WorkflowRun struct {
+	Id          string            `yaml:"id" json:"id" mapstructure:"id"`
+	Workflow    string            `yaml:"workflow" json:"workflow" mapstructure:"workflow"`
+	File        string            `yaml:"file" json:"file" mapstructure:"file"`
+	Stack       string            `yaml:"stack,omitempty" json:"stack,omitempty" mapstructure:"stack"`
+	Inputs      map[string]string `yaml:"inputs,omitempty" json:"inputs,omitempty" mapstructure:"inputs"`
+	Status      string            `yaml:"status" json:"status" mapstructure:"status"`
+	StartedAt   string            `yaml:"started_at" json:"started_at" mapstructure:"started_at"`
+	CompletedAt string            `yaml:"completed_at,omitempty" json:"completed_at,omitempty" mapstructure:"completed_at"`
+	Steps       []WorkflowRunStep `yaml:"steps" json:"steps" mapstructure:"steps"`
+}
+
+type WorkflowState struct {
+	Runs []WorkflowRun `yaml:"runs" json:"runs" mapstructure:"runs"`
+}
+
 type WorkflowFile map[string]WorkflowConfig
 
 // EKS update-kubeconfig
@@ -220,6 +293,55 @@ type VendorComponentConfig struct {
 	Spec       VendorComponentSpec `yaml:"spec" json:"spec" mapstructure:"spec"`
 }
 
+// Atmos vendoring (`vendor.yaml` file)
+
+type AtmosVendorSource struct {
+	Component     string   `yaml:"component" json:"component" mapstructure:"component"`
+	Source        string   `yaml:"source" json:"source" mapstructure:"source"`
+	Version       string   `yaml:"version" json:"version" mapstructure:"version"`
+	Targets       []string `yaml:"targets" json:"targets" mapstructure:"targets"`
+	IncludedPaths []string `yaml:"included_paths" json:"included_paths" mapstructure:"included_paths"`
+	ExcludedPaths []string `yaml:"excluded_paths" json:"excluded_paths" mapstructure:"excluded_paths"`
+	Tags          []string `yaml:"tags" json:"tags" mapstructure:"tags"`
+}
+
+type AtmosVendorSpec struct {
+	Sources []AtmosVendorSource `yaml:"sources" json:"sources" mapstructure:"sources"`
+}
+
+type AtmosVendorMetadata struct {
+	Name        string `yaml:"name" json:"name" mapstructure:"name"`
+	Description string `yaml:"description" json:"description" mapstructure:"description"`
+}
+
+type AtmosVendorConfig struct {
+	ApiVersion string              `yaml:"apiVersion" json:"apiVersion" mapstructure:"apiVersion"`
+	Kind       string              `yaml:"kind" json:"kind" mapstructure:"kind"`
+	Metadata   AtmosVendorMetadata `yaml:"metadata" json:"metadata" mapstructure:"metadata"`
+	Spec       AtmosVendorSpec     `yaml:"spec" json:"spec" mapstructure:"spec"`
+}
+
+// Atmos vendoring lock file (`vendor.lock.yaml` file)
+
+type AtmosVendorLockSource struct {
+	Component string   `yaml:"component,omitempty" json:"component,omitempty" mapstructure:"component"`
+	Source    string   `yaml:"source" json:"source" mapstructure:"source"`
+	Version   string   `yaml:"version,omitempty" json:"version,omitempty" mapstructure:"version"`
+	Uri       string   `yaml:"uri" json:"uri" mapstructure:"uri"`
+	Targets   []string `yaml:"targets" json:"targets" mapstructure:"targets"`
+	Checksum  string   `yaml:"checksum" json:"checksum" mapstructure:"checksum"`
+}
+
+type AtmosVendorLockSpec struct {
+	Sources []AtmosVendorLockSource `yaml:"sources" json:"sources" mapstructure:"sources"`
+}
+
+type AtmosVendorLock struct {
+	ApiVersion string              `yaml:"apiVersion" json:"apiVersion" mapstructure:"apiVersion"`
+	Kind       string              `yaml:"kind" json:"kind" mapstructure:"kind"`
+	Spec       AtmosVendorLockSpec `yaml:"spec" json:"spec" mapstructure:"spec"`
+}
+
 // Custom CLI commands
 
 type Command struct {
@@ -354,6 +476,45 @@ type Affected struct {
 	SpaceliftStack  string `yaml:"spacelift_stack,omitempty" json:"spacelift_stack,omitempty" mapstructure:"spacelift_stack"`
 	AtlantisProject string `yaml:"atlantis_project,omitempty" json:"atlantis_project,omitempty" mapstructure:"atlantis_project"`
 	Affected        string `yaml:"affected" json:"affected" mapstructure:"affected"`
+	// The stack slug of the affected component that the dependant component depends on (directly or transitively)
+	DependantOf string `yaml:"dependant_of,omitempty" json:"dependant_of,omitempty" mapstructure:"dependant_of"`
+	// The changed files in the component folder or in the local modules used by the component
+	ChangedFiles []string `yaml:"changed_files,omitempty" json:"changed_files,omitempty" mapstructure:"changed_files"`
+	// The changes in the component section in the stack config
+	Diff *AffectedDiff `yaml:"diff,omitempty" json:"diff,omitempty" mapstructure:"diff"`
+}
+
+// AffectedMatrix is the list of the affected components in the format of GitHub Actions matrix (`include` entries)
+type AffectedMatrix struct {
+	Include []AffectedMatrixEntry `yaml:"include" json:"include" mapstructure:"include"`
+}
+
+type AffectedMatrixEntry struct {
+	Component       string `yaml:"component" json:"component" mapstructure:"component"`
+	ComponentType   string `yaml:"component_type" json:"component_type" mapstructure:"component_type"`
+	ComponentPath   string `yaml:"component_path" json:"component_path" mapstructure:"component_path"`
+	Stack           string `yaml:"stack" json:"stack" mapstructure:"stack"`
+	StackSlug       string `yaml:"stack_slug" json:"stack_slug" mapstructure:"stack_slug"`
+	SpaceliftStack  string `yaml:"spacelift_stack,omitempty" json:"spacelift_stack,omitempty" mapstructure:"spacelift_stack"`
+	AtlantisProject string `yaml:"atlantis_project,omitempty" json:"atlantis_project,omitempty" mapstructure:"atlantis_project"`
+	Affected        string `yaml:"affected" json:"affected" mapstructure:"affected"`
+	// The dependency level of the component: 0 if the component does not depend on other affected components,
+	// otherwise the max level of the affected components it depends on plus 1
+	Level int `yaml:"level" json:"level" mapstructure:"level"`
+}
+
+// AffectedDiff is the key-level diff of a component section between the target branch and the current branch
+type AffectedDiff struct {
+	Added   []AffectedDiffItem `yaml:"added,omitempty" json:"added,omitempty" mapstructure:"added"`
+	Removed []AffectedDiffItem `yaml:"removed,omitempty" json:"removed,omitempty" mapstructure:"removed"`
+	Changed []AffectedDiffItem `yaml:"changed,omitempty" json:"changed,omitempty" mapstructure:"changed"`
+}
+
+type AffectedDiffItem struct {
+	// The dot-separated path to the key, e.g. `vars.tags.Name`
+	Path     string `yaml:"path" json:"path" mapstructure:"path"`
+	OldValue any    `yaml:"old_value,omitempty" json:"old_value,omitempty" mapstructure:"old_value"`
+	NewValue any    `yaml:"new_value,omitempty" json:"new_value,omitempty" mapstructure:"new_value"`
 }
 
 type BaseComponentConfig struct {
@@ -381,17 +542,19 @@ type StackImport struct {
 type DependsOn map[any]Context
 
 type Dependant struct {
-	Component       string `yaml:"component" json:"component" mapstructure:"component"`
-	ComponentType   string `yaml:"component_type" json:"component_type" mapstructure:"component_type"`
-	ComponentPath   string `yaml:"component_path" json:"component_path" mapstructure:"component_path"`
-	Namespace       string `yaml:"namespace,omitempty" json:"namespace,omitempty" mapstructure:"namespace"`
-	Tenant          string `yaml:"tenant,omitempty" json:"tenant,omitempty" mapstructure:"tenant"`
-	Environment     string `yaml:"environment,omitempty" json:"environment,omitempty" mapstructure:"environment"`
-	Stage           string `yaml:"stage,omitempty" json:"stage,omitempty" mapstructure:"stage"`
-	Stack           string `yaml:"stack" json:"stack" mapstructure:"stack"`
-	StackSlug       string `yaml:"stack_slug" json:"stack_slug" mapstructure:"stack_slug"`
-	SpaceliftStack  string `yaml:"spacelift_stack,omitempty" json:"spacelift_stack,omitempty" mapstructure:"spacelift_stack"`
-	AtlantisProject string `yaml:"atlantis_project,omitempty" json:"atlantis_project,omitempty" mapstructure:"atlantis_project"`
+	Component       string   `yaml:"component" json:"component" mapstructure:"component"`
+	ComponentType   string   `yaml:"component_type" json:"component_type" mapstructure:"component_type"`
+	ComponentPath   string   `yaml:"component_path" json:"component_path" mapstructure:"component_path"`
+	Namespace       string   `yaml:"namespace,omitempty" json:"namespace,omitempty" mapstructure:"namespace"`
+	Tenant          string   `yaml:"tenant,omitempty" json:"tenant,omitempty" mapstructure:"tenant"`
+	Environment     string   `yaml:"environment,omitempty" json:"environment,omitempty" mapstructure:"environment"`
+	Stage           string   `yaml:"stage,omitempty" json:"stage,omitempty" mapstructure:"stage"`
+	Stack           string   `yaml:"stack" json:"stack" mapstructure:"stack"`
+	StackSlug       string   `yaml:"stack_slug" json:"stack_slug" mapstructure:"stack_slug"`
+	SpaceliftStack  string   `yaml:"spacelift_stack,omitempty" json:"spacelift_stack,omitempty" mapstructure:"spacelift_stack"`
+	AtlantisProject string   `yaml:"atlantis_project,omitempty" json:"atlantis_project,omitempty" mapstructure:"atlantis_project"`
+	Depth           int      `yaml:"depth,omitempty" json:"depth,omitempty" mapstructure:"depth"`
+	Path            []string `yaml:"path,omitempty" json:"path,omitempty" mapstructure:"path"`
 }
 
 // Settings
@@ -399,3 +562,59 @@ type Dependant struct {
 type Settings struct {
 	DependsOn DependsOn `yaml:"depends_on" json:"depends_on" mapstructure:"depends_on"`
 }
+
+// Dependency graph of Atmos components in Atmos stacks
+
+type DependencyGraphNode struct {
+	Id            string   `yaml:"id" json:"id" mapstructure:"id"`
+	Component     string   `yaml:"component" json:"component" mapstructure:"component"`
+	ComponentType string   `yaml:"component_type" json:"component_type" mapstructure:"component_type"`
+	ComponentPath string   `yaml:"component_path" json:"component_path" mapstructure:"component_path"`
+	Namespace     string   `yaml:"namespace,omitempty" json:"namespace,omitempty" mapstructure:"namespace"`
+	Tenant        string   `yaml:"tenant,omitempty" json:"tenant,omitempty" mapstructure:"tenant"`
+	Environment   string   `yaml:"environment,omitempty" json:"environment,omitempty" mapstructure:"environment"`
+	Stage         string   `yaml:"stage,omitempty" json:"stage,omitempty" mapstructure:"stage"`
+	Stack         string   `yaml:"stack" json:"stack" mapstructure:"stack"`
+	DependsOn     []string `yaml:"depends_on,omitempty" json:"depends_on,omitempty" mapstructure:"depends_on"`
+	Dependants    []string `yaml:"dependants,omitempty" json:"dependants,omitempty" mapstructure:"dependants"`
+}
+
+type GraphNode struct {
+	Id            string `yaml:"id" json:"id" mapstructure:"id"`
+	Type          string `yaml:"type" json:"type" mapstructure:"type"`
+	Label         string `yaml:"label" json:"label" mapstructure:"label"`
+	Component     string `yaml:"component,omitempty" json:"component,omitempty" mapstructure:"component"`
+	ComponentType string `yaml:"component_type,omitempty" json:"component_type,omitempty" mapstructure:"component_type"`
+	Stack         string `yaml:"stack,omitempty" json:"stack,omitempty" mapstructure:"stack"`
+	Abstract      bool   `yaml:"abstract,omitempty" json:"abstract,omitempty" mapstructure:"abstract"`
+}
+
+type GraphEdge struct {
+	From  string `yaml:"from" json:"from" mapstructure:"from"`
+	To    string `yaml:"to" json:"to" mapstructure:"to"`
+	Type  string `yaml:"type" json:"type" mapstructure:"type"`
+	Cycle bool   `yaml:"cycle,omitempty" json:"cycle,omitempty" mapstructure:"cycle"`
+}
+
+type Graph struct {
+	Nodes  []GraphNode `yaml:"nodes" json:"nodes" mapstructure:"nodes"`
+	Edges  []GraphEdge `yaml:"edges" json:"edges" mapstructure:"edges"`
+	Cycles [][]string  `yaml:"cycles,omitempty" json:"cycles,omitempty" mapstructure:"cycles"`
+}
+
+// ProvenanceEntry describes where a value of a config key was set
+type ProvenanceEntry struct {
+	// The stack config file (relative to the stacks base path)
+	File string `yaml:"file" json:"file" mapstructure:"file"`
+	// The line in the stack config file
+	Line int `yaml:"line" json:"line" mapstructure:"line"`
+	// The scope of the value: `global`, `component-type`, `base-component` or `component`
+	Scope string `yaml:"scope,omitempty" json:"scope,omitempty" mapstructure:"scope"`
+	// The base component if the scope is `base-component`
+	BaseComponent string `yaml:"base_component,omitempty" json:"base_component,omitempty" mapstructure:"base_component"`
+	Value         any    `yaml:"value" json:"value" mapstructure:"value"`
+}
+
+// Provenance maps the dot-separated paths of the config keys (e.g. `vars.tags.Name`, the dots in the keys are escaped with a backslash)
+// to the list of the places where the values were set, in the order of deep-merging (the last entry is the final value)
+type Provenance map[string][]ProvenanceEntry
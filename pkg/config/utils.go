@@ -182,6 +182,16 @@ func processEnvVars(cliConfig *CliConfiguration) error {
 		cliConfig.Stacks.NamePattern = stacksNamePattern
 	}
 
+	stacksMaxConcurrency := os.Getenv("ATMOS_STACKS_MAX_CONCURRENCY")
+	if len(stacksMaxConcurrency) > 0 {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_STACKS_MAX_CONCURRENCY=%s", stacksMaxConcurrency))
+		stacksMaxConcurrencyInt, err := strconv.Atoi(stacksMaxConcurrency)
+		if err != nil {
+			return err
+		}
+		cliConfig.Stacks.MaxConcurrency = stacksMaxConcurrencyInt
+	}
+
 	componentsTerraformBasePath := os.Getenv("ATMOS_COMPONENTS_TERRAFORM_BASE_PATH")
 	if len(componentsTerraformBasePath) > 0 {
 		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_COMPONENTS_TERRAFORM_BASE_PATH=%s", componentsTerraformBasePath))
@@ -268,6 +278,12 @@ func processEnvVars(cliConfig *CliConfiguration) error {
 		cliConfig.Workflows.BasePath = workflowsBasePath
 	}
 
+	workflowsStateBasePath := os.Getenv("ATMOS_WORKFLOWS_STATE_BASE_PATH")
+	if len(workflowsStateBasePath) > 0 {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_WORKFLOWS_STATE_BASE_PATH=%s", workflowsStateBasePath))
+		cliConfig.Workflows.State.BasePath = workflowsStateBasePath
+	}
+
 	jsonschemaBasePath := os.Getenv("ATMOS_SCHEMAS_JSONSCHEMA_BASE_PATH")
 	if len(jsonschemaBasePath) > 0 {
 		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_SCHEMAS_JSONSCHEMA_BASE_PATH=%s", jsonschemaBasePath))
@@ -286,6 +302,38 @@ func processEnvVars(cliConfig *CliConfiguration) error {
 		cliConfig.Schemas.Cue.BasePath = cueBasePath
 	}
 
+	vendorMaxConcurrency := os.Getenv("ATMOS_VENDOR_MAX_CONCURRENCY")
+	if len(vendorMaxConcurrency) > 0 {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_VENDOR_MAX_CONCURRENCY=%s", vendorMaxConcurrency))
+		vendorMaxConcurrencyInt, err := strconv.Atoi(vendorMaxConcurrency)
+		if err != nil {
+			return err
+		}
+		cliConfig.Vendor.MaxConcurrency = vendorMaxConcurrencyInt
+	}
+
+	vendorCacheEnabled := os.Getenv("ATMOS_VENDOR_CACHE_ENABLED")
+	if len(vendorCacheEnabled) > 0 {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_VENDOR_CACHE_ENABLED=%s", vendorCacheEnabled))
+		vendorCacheEnabledBool, err := strconv.ParseBool(vendorCacheEnabled)
+		if err != nil {
+			return err
+		}
+		cliConfig.Vendor.Cache.Enabled = vendorCacheEnabledBool
+	}
+
+	vendorCacheBasePath := os.Getenv("ATMOS_VENDOR_CACHE_BASE_PATH")
+	if len(vendorCacheBasePath) > 0 {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_VENDOR_CACHE_BASE_PATH=%s", vendorCacheBasePath))
+		cliConfig.Vendor.Cache.BasePath = vendorCacheBasePath
+	}
+
+	listMergeStrategy := os.Getenv("ATMOS_SETTINGS_LIST_MERGE_STRATEGY")
+	if len(listMergeStrategy) > 0 {
+		u.PrintInfoVerbose(cliConfig.Logs.Verbose, fmt.Sprintf("Found ENV var ATMOS_SETTINGS_LIST_MERGE_STRATEGY=%s", listMergeStrategy))
+		cliConfig.Settings.ListMergeStrategy = listMergeStrategy
+	}
+
 	return nil
 }
 
@@ -298,9 +346,30 @@ func checkConfig(cliConfig CliConfiguration) error {
 		return errors.New("at least one path must be provided in 'stacks.included_paths' config or ATMOS_STACKS_INCLUDED_PATHS' ENV variable")
 	}
 
+	if !isValidListMergeStrategy(cliConfig.Settings.ListMergeStrategy) {
+		return fmt.Errorf("invalid 'settings.list_merge_strategy' config value '%s'. Supported strategies: %s, %s, %s",
+			cliConfig.Settings.ListMergeStrategy, ListMergeStrategyReplace, ListMergeStrategyAppend, ListMergeStrategyMerge)
+	}
+
+	for key, strategy := range cliConfig.Settings.ListMergeStrategyOverrides {
+		if strategy == "" || !isValidListMergeStrategy(strategy) {
+			return fmt.Errorf("invalid 'settings.list_merge_strategy_overrides.%s' config value '%s'. Supported strategies: %s, %s, %s",
+				key, strategy, ListMergeStrategyReplace, ListMergeStrategyAppend, ListMergeStrategyMerge)
+		}
+	}
+
 	return nil
 }
 
+// isValidListMergeStrategy checks if the list merge strategy is supported (an empty strategy means the default `replace` strategy)
+func isValidListMergeStrategy(strategy string) bool {
+	switch strategy {
+	case "", ListMergeStrategyReplace, ListMergeStrategyAppend, ListMergeStrategyMerge:
+		return true
+	}
+	return false
+}
+
 func processCommandLineArgs(cliConfig *CliConfiguration, configAndStacksInfo ConfigAndStacksInfo) error {
 	if len(configAndStacksInfo.BasePath) > 0 {
 		cliConfig.BasePath = configAndStacksInfo.BasePath
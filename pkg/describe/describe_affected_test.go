@@ -2,11 +2,19 @@ package describe
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	cp "github.com/otiai10/copy"
+
 	e "github.com/cloudposse/atmos/internal/exec"
 	cfg "github.com/cloudposse/atmos/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
-	"testing"
 )
 
 func TestDescribeAffectedWithTargetRepoClone(t *testing.T) {
@@ -25,7 +33,7 @@ func TestDescribeAffectedWithTargetRepoClone(t *testing.T) {
 	ref := "refs/heads/master"
 	sha := ""
 
-	affected, err := e.ExecuteDescribeAffectedWithTargetRepoClone(cliConfig, ref, sha, "", "", true)
+	affected, _, err := e.ExecuteDescribeAffectedWithTargetRepoClone(cliConfig, ref, sha, "", "", true, false, false)
 	assert.Nil(t, err)
 
 	affectedYaml, err := yaml.Marshal(affected)
@@ -49,7 +57,7 @@ func TestDescribeAffectedWithTargetRepoPath(t *testing.T) {
 	// This will compare this local repository with itself as the remote target, which should result in an empty `affected` list
 	repoPath := "../../"
 
-	affected, err := e.ExecuteDescribeAffectedWithTargetRepoPath(cliConfig, repoPath, true)
+	affected, _, err := e.ExecuteDescribeAffectedWithTargetRepoPath(cliConfig, repoPath, true, false, false)
 	assert.Nil(t, err)
 
 	affectedYaml, err := yaml.Marshal(affected)
@@ -57,3 +65,257 @@ func TestDescribeAffectedWithTargetRepoPath(t *testing.T) {
 
 	t.Log(fmt.Sprintf("\nAffected components and stacks:\n%v", string(affectedYaml)))
 }
+
+func TestDescribeAffectedIncludeDependants(t *testing.T) {
+	// `eks` depends on `vpc`, `app` depends on `eks` and `dns`, `dns` does not depend on anything
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/include-dependants/*")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	stacks, err := e.ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, false)
+	assert.Nil(t, err)
+
+	affected := []cfg.Affected{
+		{Component: "vpc", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-vpc", Affected: "stack.vars"},
+	}
+
+	affected, err = e.AddDependantsToAffected(cliConfig, stacks, affected)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(affected))
+
+	assert.Equal(t, "eks", affected[1].Component)
+	assert.Equal(t, "dependant", affected[1].Affected)
+	assert.Equal(t, "tests-ue2-dev-vpc", affected[1].DependantOf)
+	assert.Equal(t, "tests-ue2-dev-eks", affected[1].StackSlug)
+
+	assert.Equal(t, "app", affected[2].Component)
+	assert.Equal(t, "dependant", affected[2].Affected)
+	assert.Equal(t, "tests-ue2-dev-vpc", affected[2].DependantOf)
+
+	// `app` depends on both affected components. The first of them (sorted by stack and component) is used
+	// regardless of the order of the affected list
+	for _, affectedList := range [][]cfg.Affected{
+		{
+			{Component: "vpc", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-vpc", Affected: "stack.vars"},
+			{Component: "dns", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-dns", Affected: "stack.vars"},
+		},
+		{
+			{Component: "dns", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-dns", Affected: "stack.vars"},
+			{Component: "vpc", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-vpc", Affected: "stack.vars"},
+		},
+	} {
+		affected, err = e.AddDependantsToAffected(cliConfig, stacks, affectedList)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(affected))
+
+		assert.Equal(t, "app", affected[2].Component)
+		assert.Equal(t, "tests-ue2-dev-dns", affected[2].DependantOf)
+		assert.Equal(t, "eks", affected[3].Component)
+		assert.Equal(t, "tests-ue2-dev-vpc", affected[3].DependantOf)
+	}
+}
+
+func TestDescribeAffectedWithBase(t *testing.T) {
+	// `eks` depends on `vpc`, and the `vpc` vars are changed in the current commit
+	cliConfig, base := newDescribeAffectedFixtureRepo(t, "base")
+
+	affected, _, err := e.ExecuteDescribeAffectedWithBase(cliConfig, base, false, true, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(affected))
+
+	assert.Equal(t, "vpc", affected[0].Component)
+	assert.Equal(t, "stack.vars", affected[0].Affected)
+	assert.NotNil(t, affected[0].Diff)
+	assert.Equal(t, []cfg.AffectedDiffItem{{Path: "vars.cidr", OldValue: "10.0.0.0/16", NewValue: "10.1.0.0/16"}}, affected[0].Diff.Changed)
+
+	assert.Equal(t, "eks", affected[1].Component)
+	assert.Equal(t, "dependant", affected[1].Affected)
+	assert.Equal(t, "dev-vpc", affected[1].DependantOf)
+
+	_, _, err = e.ExecuteDescribeAffectedWithBase(cliConfig, "refs/heads/does-not-exist", false, false, false)
+	assert.NotNil(t, err)
+}
+
+func TestDescribeAffectedMatrix(t *testing.T) {
+	// `eks` depends on `vpc`, `app` depends on `eks`, `dns` does not depend on anything
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/matrix/*")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	stacks, err := e.ExecuteDescribeStacks(cliConfig, "", nil, nil, nil, false)
+	assert.Nil(t, err)
+
+	// `eks` is not affected, but `app` still depends on `vpc` through it.
+	// `db` is not in the stacks
+	affected := []cfg.Affected{
+		{Component: "app", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-app", Affected: "stack.vars"},
+		{Component: "dns", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-dns", Affected: "stack.vars"},
+		{Component: "vpc", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-vpc", Affected: "component"},
+		{Component: "db", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-db", Affected: "component"},
+	}
+
+	matrix, err := e.BuildAffectedMatrix(cliConfig, stacks, affected)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(matrix.Include))
+
+	assert.Equal(t, "db", matrix.Include[0].Component)
+	assert.Equal(t, 0, matrix.Include[0].Level)
+	assert.Equal(t, "dns", matrix.Include[1].Component)
+	assert.Equal(t, 0, matrix.Include[1].Level)
+	assert.Equal(t, "vpc", matrix.Include[2].Component)
+	assert.Equal(t, 0, matrix.Include[2].Level)
+	assert.Equal(t, "app", matrix.Include[3].Component)
+	assert.Equal(t, 1, matrix.Include[3].Level)
+
+	// The components affected for more than one reason are included once per stack, with the lowest level.
+	// The Helmfile component `app` is not in the stacks, so it's at the level 0
+	affected = append(affected,
+		cfg.Affected{Component: "vpc", ComponentType: "terraform", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-vpc", Affected: "stack.vars"},
+		cfg.Affected{Component: "app", ComponentType: "helmfile", Stack: "tests-ue2-dev", StackSlug: "tests-ue2-dev-app", Affected: "component"},
+	)
+
+	matrix, err = e.BuildAffectedMatrix(cliConfig, stacks, affected)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(matrix.Include))
+
+	assert.Equal(t, "app", matrix.Include[0].Component)
+	assert.Equal(t, "helmfile", matrix.Include[0].ComponentType)
+	assert.Equal(t, 0, matrix.Include[0].Level)
+	assert.Equal(t, "vpc", matrix.Include[3].Component)
+	assert.Equal(t, "component", matrix.Include[3].Affected)
+}
+
+// newDescribeAffectedFixtureRepo creates a Git repo in a temp dir from the scenario in the `examples/complete/tests/describe-affected` folder:
+// it commits `atmos.yaml` and the `base` folder of the scenario, and then the `current` folder of the scenario copied over them.
+// It changes the current dir to the repo (`ExecuteDescribeAffectedWithBase` uses the Git repo of the current dir),
+// and returns the CLI config and the hash of the base commit
+func newDescribeAffectedFixtureRepo(t *testing.T, scenario string) (cfg.CliConfiguration, string) {
+	t.Helper()
+
+	fixturesPath := "../../examples/complete/tests/describe-affected"
+	repoPath := t.TempDir()
+
+	err := cp.Copy(path.Join(fixturesPath, "atmos.yaml"), path.Join(repoPath, "atmos.yaml"))
+	assert.Nil(t, err)
+	err = cp.Copy(path.Join(fixturesPath, scenario, "base"), repoPath)
+	assert.Nil(t, err)
+
+	repo, err := git.PlainInit(repoPath, false)
+	assert.Nil(t, err)
+	worktree, err := repo.Worktree()
+	assert.Nil(t, err)
+
+	commitOptions := &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}}
+
+	_, err = worktree.Add(".")
+	assert.Nil(t, err)
+	base, err := worktree.Commit("base", commitOptions)
+	assert.Nil(t, err)
+
+	err = cp.Copy(path.Join(fixturesPath, scenario, "current"), repoPath)
+	assert.Nil(t, err)
+
+	_, err = worktree.Add(".")
+	assert.Nil(t, err)
+	_, err = worktree.Commit("current", commitOptions)
+	assert.Nil(t, err)
+
+	cwd, err := os.Getwd()
+	assert.Nil(t, err)
+	err = os.Chdir(repoPath)
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+
+	t.Setenv("ATMOS_CLI_CONFIG_PATH", repoPath)
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	return cliConfig, base.String()
+}
+
+func TestDescribeAffectedWithChangedTerraformModule(t *testing.T) {
+	// `vpc` uses the local module `foo`, which uses the local module `bar`, and the template in a subfolder of `bar` is changed
+	cliConfig, base := newDescribeAffectedFixtureRepo(t, "terraform-module")
+
+	affected, _, err := e.ExecuteDescribeAffectedWithBase(cliConfig, base, false, false, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(affected))
+
+	assert.Equal(t, "vpc", affected[0].Component)
+	assert.Equal(t, "component.module", affected[0].Affected)
+	assert.Equal(t, []string{"components/modules/bar/templates/policy.json"}, affected[0].ChangedFiles)
+}
+
+func TestDescribeAffectedWithChangedBackendAndCommand(t *testing.T) {
+	// The backend, remote state backend and command sections are changed, added and removed in the components
+	cliConfig, base := newDescribeAffectedFixtureRepo(t, "backend-and-command")
+
+	affected, _, err := e.ExecuteDescribeAffectedWithBase(cliConfig, base, false, false, true)
+	assert.Nil(t, err)
+
+	affectedByComponent := map[string]cfg.Affected{}
+	for _, a := range affected {
+		affectedByComponent[a.Component] = a
+	}
+
+	assert.Equal(t, 6, len(affected))
+	assert.Equal(t, "stack.backend_type", affectedByComponent["backend-type"].Affected)
+	assert.Equal(t, "stack.backend", affectedByComponent["backend"].Affected)
+	assert.Equal(t, "stack.remote_state_backend_type", affectedByComponent["remote-state-backend-type"].Affected)
+	assert.Equal(t, "stack.command", affectedByComponent["command"].Affected)
+
+	// The sections that exist in the target branch but were removed in the current branch
+	assert.Equal(t, "stack.backend", affectedByComponent["backend-removed"].Affected)
+	assert.Equal(t, []cfg.AffectedDiffItem{{Path: "backend.acl", OldValue: "private"}}, affectedByComponent["backend-removed"].Diff.Removed)
+
+	assert.Equal(t, "stack.remote_state_backend", affectedByComponent["remote-state-backend-removed"].Affected)
+	assert.Equal(t, []cfg.AffectedDiffItem{{Path: "remote_state_backend.role_arn", OldValue: "arn:aws:iam::123456789012:role/tfstate"}}, affectedByComponent["remote-state-backend-removed"].Diff.Removed)
+
+	assert.Equal(t, []cfg.AffectedDiffItem{{Path: "command", OldValue: "terraform", NewValue: "tofu"}}, affectedByComponent["command"].Diff.Changed)
+}
+
+func TestDescribeAffectedWithDiff(t *testing.T) {
+	// The keys of the `vpc` vars are added, removed and changed, and a file in the `dns` component folder is changed
+	cliConfig, base := newDescribeAffectedFixtureRepo(t, "diff")
+
+	affected, _, err := e.ExecuteDescribeAffectedWithBase(cliConfig, base, false, false, true)
+	assert.Nil(t, err)
+
+	affectedByComponent := map[string]cfg.Affected{}
+	for _, a := range affected {
+		affectedByComponent[a.Component] = a
+	}
+
+	assert.Equal(t, 2, len(affected))
+
+	// The added, removed and changed keys of the stack config section, including the nested maps and the lists
+	vpc := affectedByComponent["vpc"]
+	assert.Equal(t, "stack.vars", vpc.Affected)
+	assert.NotNil(t, vpc.Diff)
+	assert.Nil(t, vpc.ChangedFiles)
+	assert.Equal(t, []cfg.AffectedDiffItem{{Path: "vars.max_subnet_count", NewValue: 4}}, vpc.Diff.Added)
+	assert.Equal(t, []cfg.AffectedDiffItem{{Path: "vars.nat_gateway_enabled", OldValue: true}}, vpc.Diff.Removed)
+	assert.Equal(t, []cfg.AffectedDiffItem{
+		{Path: "vars.availability_zones", OldValue: []any{"us-east-2a", "us-east-2b"}, NewValue: []any{"us-east-2a", "us-east-2c"}},
+		{Path: "vars.tags.subnets.public", OldValue: 2, NewValue: 1},
+	}, vpc.Diff.Changed)
+
+	// The changed files in the component folder
+	dns := affectedByComponent["dns"]
+	assert.Equal(t, "component", dns.Affected)
+	assert.Nil(t, dns.Diff)
+	assert.Equal(t, []string{"components/terraform/dns/main.tf"}, dns.ChangedFiles)
+
+	// Without `includeDiff`, the details of the changes are not added
+	affected, _, err = e.ExecuteDescribeAffectedWithBase(cliConfig, base, false, false, false)
+	assert.Nil(t, err)
+	for _, a := range affected {
+		assert.Nil(t, a.Diff)
+		assert.Nil(t, a.ChangedFiles)
+	}
+}
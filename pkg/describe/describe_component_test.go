@@ -1,10 +1,12 @@
 package describe
 
 import (
+	"testing"
+
 	e "github.com/cloudposse/atmos/internal/exec"
+	cfg "github.com/cloudposse/atmos/pkg/config"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
-	"testing"
 )
 
 func TestDescribeComponent(t *testing.T) {
@@ -28,3 +30,35 @@ func TestDescribeTemplatedComponent(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "eks-green", componentSection["vars"].(map[any]any)["name"])
 }
+
+func TestDescribeComponentWithProvenance(t *testing.T) {
+	// `vpc` inherits from the abstract component `vpc-defaults` from the imported catalog file
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/provenance/*")
+
+	componentSection, provenance, err := e.ExecuteDescribeComponentWithProvenance("vpc", "tests-ue2-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.1.0.0/16", componentSection["vars"].(map[any]any)["cidr"])
+
+	assert.Equal(t, []cfg.ProvenanceEntry{
+		{File: "tests/provenance/catalog/defaults.yaml", Line: 15, Scope: "base-component", BaseComponent: "vpc-defaults", Value: "10.0.0.0/16"},
+		{File: "tests/provenance/dev.yaml", Line: 12, Scope: "component", Value: "10.1.0.0/16"},
+	}, provenance["vars.cidr"])
+
+	assert.Equal(t, []cfg.ProvenanceEntry{
+		{File: "tests/provenance/catalog/defaults.yaml", Line: 3, Scope: "global", Value: "us-east-2"},
+		{File: "tests/provenance/catalog/defaults.yaml", Line: 7, Scope: "component-type", Value: "us-west-2"},
+		{File: "tests/provenance/catalog/defaults.yaml", Line: 16, Scope: "base-component", BaseComponent: "vpc-defaults", Value: "eu-west-1"},
+	}, provenance["vars.region"])
+
+	assert.Equal(t, 1, len(provenance["vars.stage"]))
+
+	// The keys with dots don't collide with the nested keys
+	assert.Equal(t, []cfg.ProvenanceEntry{
+		{File: "tests/provenance/catalog/defaults.yaml", Line: 19, Scope: "base-component", BaseComponent: "vpc-defaults", Value: "shared"},
+		{File: "tests/provenance/dev.yaml", Line: 14, Scope: "component", Value: "owned"},
+	}, provenance[`vars.tags.kubernetes\.io/cluster/eks`])
+
+	assert.Equal(t, []cfg.ProvenanceEntry{
+		{File: "tests/provenance/catalog/defaults.yaml", Line: 21, Scope: "base-component", BaseComponent: "vpc-defaults", Value: "nested"},
+	}, provenance["vars.tags.kubernetes.io/cluster/eks"])
+}
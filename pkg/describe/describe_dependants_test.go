@@ -25,3 +25,41 @@ func TestDescribeDependants(t *testing.T) {
 	assert.Nil(t, err)
 	t.Log(string(dependantsYaml))
 }
+
+func TestDescribeDependantsTransitive(t *testing.T) {
+	// `db` and `cache` depend on `network`, `app` depends on `db`
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/dependants-transitive/*")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	dependants, err := e.ExecuteDescribeDependants(cliConfig, "network", "tests-ue2-dev")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(dependants))
+
+	dependants, err = e.ExecuteDescribeDependantsTransitive(cliConfig, "network", "tests-ue2-dev", 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(dependants))
+
+	depths := map[string]int{}
+	for _, dependant := range dependants {
+		depths[dependant.Component] = dependant.Depth
+		if dependant.Component == "app" {
+			assert.Equal(t, []string{"tests-ue2-dev-network", "tests-ue2-dev-db", "tests-ue2-dev-app"}, dependant.Path)
+		}
+	}
+	assert.Equal(t, map[string]int{"db": 1, "cache": 1, "app": 2}, depths)
+
+	// The dependants are sorted by stack and component at each depth
+	assert.Equal(t, "cache", dependants[0].Component)
+	assert.Equal(t, "tests-ue2-dev-cache", dependants[0].StackSlug)
+	assert.Equal(t, "terraform", dependants[0].ComponentType)
+	assert.Equal(t, "dev", dependants[0].Stage)
+
+	dependants, err = e.ExecuteDescribeDependantsTransitive(cliConfig, "network", "tests-ue2-dev", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(dependants))
+
+	_, err = e.ExecuteDescribeDependantsTransitive(cliConfig, "missing", "tests-ue2-dev", 0)
+	assert.NotNil(t, err)
+}
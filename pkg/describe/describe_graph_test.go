@@ -0,0 +1,90 @@
+package describe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	e "github.com/cloudposse/atmos/internal/exec"
+	cfg "github.com/cloudposse/atmos/pkg/config"
+)
+
+func TestDescribeGraph(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	graph, err := e.ExecuteDescribeGraph(cliConfig, "tenant1-ue2-dev", []string{"top-level-component1", "test/test-component-override-3"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(graph.Cycles))
+
+	assert.Contains(t, graph.Edges, cfg.GraphEdge{
+		From: "component:terraform/tenant1-ue2-dev/top-level-component1",
+		To:   "component:terraform/tenant1-ue2-dev/test/test-component-override",
+		Type: "depends_on",
+	})
+
+	assert.Contains(t, graph.Edges, cfg.GraphEdge{
+		From: "component:terraform/tenant1-ue2-dev/test/test-component-override-3",
+		To:   "component:terraform/tenant1-ue2-dev/mixin/test-1",
+		Type: "inherits",
+	})
+
+	assert.Contains(t, graph.Edges, cfg.GraphEdge{
+		From: "stack:tenant1-ue2-dev",
+		To:   "import:mixins/stage/dev",
+		Type: "imports",
+	})
+
+	// Only the `depends_on` edges
+	graph, err = e.ExecuteDescribeGraph(cliConfig, "tenant1-ue2-dev", []string{"top-level-component1"}, []string{"depends_on"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(graph.Edges))
+	assert.Equal(t, 3, len(graph.Nodes))
+
+	graphYaml, err := yaml.Marshal(graph)
+	assert.Nil(t, err)
+	t.Log(string(graphYaml))
+}
+
+func TestDescribeGraphCycles(t *testing.T) {
+	// `a` -> `b` -> `c` -> `a` is a cycle, `d` depends on `a` but is not in the cycle
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/graph-cycles/*")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	graph, err := e.ExecuteDescribeGraph(cliConfig, "", nil, []string{"depends_on"})
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"component:terraform/tests-ue2-dev/a", "component:terraform/tests-ue2-dev/b", "component:terraform/tests-ue2-dev/c"}}, graph.Cycles)
+
+	cycleEdges := 0
+	for _, edge := range graph.Edges {
+		if edge.Cycle {
+			cycleEdges++
+		}
+	}
+	assert.Equal(t, 3, cycleEdges)
+	assert.Equal(t, 4, len(graph.Edges))
+}
+
+func TestDescribeGraphComponentIds(t *testing.T) {
+	// `a/b` and `a-b` have the same stack slug, and the terraform and helmfile components have the same name
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/graph-component-ids/*")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	graph, err := e.ExecuteDescribeGraph(cliConfig, "", nil, []string{"depends_on"})
+	assert.Nil(t, err)
+
+	var ids []string
+	for _, node := range graph.Nodes {
+		ids = append(ids, node.Id)
+	}
+	assert.Equal(t, []string{"component:helmfile/tests-ue2-dev/a-b", "component:terraform/tests-ue2-dev/a-b", "component:terraform/tests-ue2-dev/a/b"}, ids)
+
+	assert.Equal(t, []cfg.GraphEdge{
+		{From: "component:terraform/tests-ue2-dev/a-b", To: "component:terraform/tests-ue2-dev/a/b", Type: "depends_on"},
+	}, graph.Edges)
+}
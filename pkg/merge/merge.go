@@ -1,65 +1,305 @@
 package merge
 
 import (
+	"fmt"
+	"reflect"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
 	u "github.com/cloudposse/atmos/pkg/utils"
-	"github.com/imdario/mergo"
-	"gopkg.in/yaml.v2"
 )
 
-// MergeWithOptions takes a list of maps of interface and options as input and returns a single map with the merged contents
+// listMergeOptions holds the list merge strategies used when deep-merging maps
+type listMergeOptions struct {
+	strategy  string
+	overrides map[string]string
+}
+
+// strategyForKey returns the list merge strategy for the list with the key (the key name only, not the path to the list)
+func (o listMergeOptions) strategyForKey(key any) string {
+	if len(o.overrides) > 0 {
+		if s, ok := o.overrides[fmt.Sprintf("%v", key)]; ok {
+			return s
+		}
+	}
+	return o.strategy
+}
+
+// MergeWithOptions takes a list of maps of interface and options as input and returns a single map with the merged contents.
+// If `appendSlice` is true, the lists are appended. If `sliceDeepCopy` is true, the items of the lists with the same index are deep-merged
 func MergeWithOptions(inputs []map[any]any, appendSlice, sliceDeepCopy bool) (map[any]any, error) {
-	merged := map[any]any{}
+	strategy := cfg.ListMergeStrategyReplace
+	if appendSlice {
+		strategy = cfg.ListMergeStrategyAppend
+	} else if sliceDeepCopy {
+		strategy = cfg.ListMergeStrategyMerge
+	}
+
+	return MergeWithListStrategies(inputs, strategy, nil)
+}
+
+// MergeWithListStrategies takes a list of maps of interface as input and returns a single map with the merged contents.
+// The lists are merged using the `listMergeStrategy` (`replace`, `append` or `merge`).
+// `listMergeStrategyOverrides` is a map of key names to list merge strategies, which override the default strategy for the lists with these keys.
+// The key names are matched against the key of the list at any level of the maps (not against dotted paths),
+// since the inputs can be whole stack configs or their sections
+// The inputs are not modified, and the result does not share any maps or lists with the inputs
+func MergeWithListStrategies(inputs []map[any]any, listMergeStrategy string, listMergeStrategyOverrides map[string]string) (map[any]any, error) {
+	opts := listMergeOptions{
+		strategy:  listMergeStrategy,
+		overrides: listMergeStrategyOverrides,
+	}
+
+	if err := checkListMergeStrategy(opts.strategy, ""); err != nil {
+		u.PrintErrorToStdError(err)
+		return nil, err
+	}
+	for k, s := range opts.overrides {
+		if err := checkListMergeStrategy(s, k); err != nil {
+			u.PrintErrorToStdError(err)
+			return nil, err
+		}
+	}
 
-	for index := range inputs {
-		current := inputs[index]
+	merged := map[any]any{}
 
+	for _, current := range inputs {
 		if len(current) == 0 {
 			continue
 		}
 
-		// Due to a bug in `mergo.Merge`
-		// (Note: in the `for` loop, it DOES modify the source of the previous loop iteration if it's a complex map and `mergo` gets a pointer to it,
-		// not only the destination of the current loop iteration),
-		// we don't give it our maps directly; we convert them to YAML strings and then back to `Go` maps,
-		// so `mergo` does not have access to the original pointers
-		yamlCurrent, err := yaml.Marshal(current)
-		if err != nil {
+		if err := mergeMaps(merged, current, opts); err != nil {
 			u.PrintErrorToStdError(err)
 			return nil, err
 		}
+	}
 
-		var dataCurrent map[any]any
-		if err = yaml.Unmarshal(yamlCurrent, &dataCurrent); err != nil {
-			u.PrintErrorToStdError(err)
-			return nil, err
+	return merged, nil
+}
+
+// Merge takes a list of maps of interface as input and returns a single map with the merged contents.
+// The lists are merged using the list merge strategies from the `settings` section in `atmos.yaml`
+func Merge(cliConfig cfg.CliConfiguration, inputs []map[any]any) (map[any]any, error) {
+	return MergeWithListStrategies(inputs, cliConfig.Settings.ListMergeStrategy, cliConfig.Settings.ListMergeStrategyOverrides)
+}
+
+// checkListMergeStrategy checks if the list merge strategy is supported
+func checkListMergeStrategy(strategy string, key string) error {
+	switch strategy {
+	case "", cfg.ListMergeStrategyReplace, cfg.ListMergeStrategyAppend, cfg.ListMergeStrategyMerge:
+		return nil
+	}
+
+	if key != "" {
+		return fmt.Errorf("invalid list merge strategy '%s' for the key '%s'. Supported strategies: %s, %s, %s",
+			strategy, key, cfg.ListMergeStrategyReplace, cfg.ListMergeStrategyAppend, cfg.ListMergeStrategyMerge)
+	}
+	return fmt.Errorf("invalid list merge strategy '%s'. Supported strategies: %s, %s, %s",
+		strategy, cfg.ListMergeStrategyReplace, cfg.ListMergeStrategyAppend, cfg.ListMergeStrategyMerge)
+}
+
+// mergeMaps deep-merges the `src` map into the `dst` map.
+// `dst` must be owned by the caller (it's modified in place). `src` is not modified, and its maps and lists are copied into `dst`.
+// The values from `src` override the values in `dst` with the following rules:
+//   - `null` overrides any value
+//   - a map is deep-merged into a map; a map replaces an empty value, and does not override any other non-empty value
+//   - a list is merged with a list using the list merge strategy; a list replaces an empty value or `null`,
+//     and it's an error to override any other value with a list
+//   - any other value overrides any value
+func mergeMaps(dst map[any]any, src map[any]any, opts listMergeOptions) error {
+	for k, srcValue := range src {
+		dstValue, dstExists := dst[k]
+
+		if srcValue == nil {
+			dst[k] = nil
+			continue
 		}
 
-		var opts []func(*mergo.Config)
-		opts = append(opts, mergo.WithOverride, mergo.WithTypeCheck)
+		srcValue = normalizeValue(srcValue)
+
+		switch srcTyped := srcValue.(type) {
+		case map[any]any:
+			if !dstExists || isEmptyValue(dstValue) {
+				dst[k] = deepCopyValue(srcTyped)
+				continue
+			}
+
+			if dstTyped, ok := dstValue.(map[any]any); ok {
+				if err := mergeMaps(dstTyped, srcTyped, opts); err != nil {
+					return err
+				}
+			}
+
+		case []any:
+			if !dstExists || dstValue == nil {
+				dst[k] = deepCopyValue(srcTyped)
+				continue
+			}
 
-		// This was fixed/broken in https://github.com/imdario/mergo/pull/231/files
-		// It was released in https://github.com/imdario/mergo/releases/tag/v0.3.14
-		// It was not working before in `github.com/imdario/mergo` so we need to disable it in our code
-		// opts = append(opts, mergo.WithOverwriteWithEmptyValue)
+			dstTyped, ok := dstValue.([]any)
+			if !ok {
+				return fmt.Errorf("cannot override two slices with different type (%T, %T)", srcTyped, dstValue)
+			}
 
-		if appendSlice {
-			opts = append(opts, mergo.WithAppendSlice)
+			list, err := mergeListValues(dstTyped, srcTyped, opts.strategyForKey(k), opts)
+			if err != nil {
+				return err
+			}
+			dst[k] = list
+
+		default:
+			dst[k] = srcValue
 		}
+	}
 
-		if sliceDeepCopy {
-			opts = append(opts, mergo.WithSliceDeepCopy)
+	return nil
+}
+
+// mergeListValues merges the `src` list into the `dst` list using the list merge strategy.
+// `dst` must be owned by the caller. `src` is not modified
+func mergeListValues(dst []any, src []any, strategy string, opts listMergeOptions) ([]any, error) {
+	switch strategy {
+	case cfg.ListMergeStrategyAppend:
+		list := make([]any, 0, len(dst)+len(src))
+		list = append(list, dst...)
+		for _, v := range src {
+			list = append(list, deepCopyValue(v))
 		}
+		return list, nil
 
-		if err = mergo.Merge(&merged, dataCurrent, opts...); err != nil {
-			u.PrintErrorToStdError(err)
-			return nil, err
+	case cfg.ListMergeStrategyMerge:
+		// The items of the lists with the same index are deep-merged if both items are maps, otherwise the item from `src` wins.
+		// The result has the length of the longest list
+		length := len(dst)
+		if len(src) > length {
+			length = len(src)
+		}
+
+		list := make([]any, length)
+		for i := 0; i < length; i++ {
+			if i >= len(src) {
+				list[i] = dst[i]
+				continue
+			}
+
+			srcItem := normalizeValue(src[i])
+			if i < len(dst) {
+				dstItem, dstIsMap := dst[i].(map[any]any)
+				srcItemMap, srcIsMap := srcItem.(map[any]any)
+				if dstIsMap && srcIsMap {
+					if err := mergeMaps(dstItem, srcItemMap, opts); err != nil {
+						return nil, err
+					}
+					list[i] = dstItem
+					continue
+				}
+			}
+
+			list[i] = deepCopyValue(srcItem)
 		}
+		return list, nil
 	}
 
-	return merged, nil
+	return deepCopyValue(src).([]any), nil
 }
 
-// Merge takes a list of maps of interface as input and returns a single map with the merged contents
-func Merge(inputs []map[any]any) (map[any]any, error) {
-	return MergeWithOptions(inputs, false, false)
+// normalizeValue converts the maps and lists of any type (e.g. `map[string]any` or `[]string`) to `map[any]any` and `[]any`,
+// which are the types produced by parsing YAML. The maps and lists are not copied if they already have these types
+func normalizeValue(value any) any {
+	switch value.(type) {
+	case nil, map[any]any, []any, string, bool, int, float64:
+		return value
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Map:
+		m := make(map[any]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().Interface()] = iter.Value().Interface()
+		}
+		return m
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		l := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			l[i] = v.Index(i).Interface()
+		}
+		return l
+	}
+
+	return value
+}
+
+// DeepCopy returns a deep copy of the map, converting all the nested maps and lists to `map[any]any` and `[]any`
+func DeepCopy(value map[any]any) map[any]any {
+	if value == nil {
+		return nil
+	}
+	return deepCopyValue(value).(map[any]any)
+}
+
+// deepCopyValue returns a deep copy of the value, converting all the maps and lists to `map[any]any` and `[]any`
+func deepCopyValue(value any) any {
+	switch typed := normalizeValue(value).(type) {
+	case map[any]any:
+		m := make(map[any]any, len(typed))
+		for k, v := range typed {
+			m[k] = deepCopyValue(v)
+		}
+		return m
+
+	case []any:
+		l := make([]any, len(typed))
+		for i, v := range typed {
+			l[i] = deepCopyValue(v)
+		}
+		return l
+
+	default:
+		return typed
+	}
+}
+
+// isEmptyValue checks if the value is `null`, an empty string, map or list, `false`, or a zero number
+func isEmptyValue(value any) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case bool:
+		return !typed
+	case int:
+		return typed == 0
+	case float64:
+		return typed == 0
+	case map[any]any:
+		return len(typed) == 0
+	case []any:
+		return len(typed) == 0
+	}
+
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
 }
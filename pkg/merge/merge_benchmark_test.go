@@ -0,0 +1,43 @@
+package merge
+
+import (
+	"testing"
+)
+
+// go test -run '^$' -bench . -benchmem ./pkg/merge
+
+func benchmarkMerge(b *testing.B, merge func([]map[any]any, string, map[string]string) (map[any]any, error)) {
+	// Deep-merge the sections of all the stack config fixtures, similar to how the sections are deep-merged when processing stacks
+	fixtures := loadStackConfigFixtures(b)
+	var sections [][]map[any]any
+	for _, section := range []string{"vars", "settings", "env", "terraform", "helmfile", "components"} {
+		var inputs []map[any]any
+		for _, f := range fixtures {
+			if m, ok := f[section].(map[any]any); ok {
+				inputs = append(inputs, m)
+			}
+		}
+		sections = append(sections, inputs)
+	}
+
+	for _, s := range propertyTestListMergeStrategies {
+		b.Run(s.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, inputs := range sections {
+					if _, err := merge(inputs, s.strategy, s.overrides); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMerge(b *testing.B) {
+	benchmarkMerge(b, MergeWithListStrategies)
+}
+
+func BenchmarkMergeLegacy(b *testing.B) {
+	benchmarkMerge(b, legacyMergeWithListStrategies)
+}
@@ -0,0 +1,199 @@
+package merge
+
+// This file contains the previous implementation of the deep-merge, which converts the maps to YAML and back
+// and uses `mergo` to deep-merge them.
+// It's used in the tests and benchmarks to check that the native deep-merge produces the same results
+
+import (
+	"fmt"
+
+	"github.com/imdario/mergo"
+	"gopkg.in/yaml.v2"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+)
+
+// legacyMergeWithOptions takes a list of maps of interface and options as input and returns a single map with the merged contents
+func legacyMergeWithOptions(inputs []map[any]any, appendSlice, sliceDeepCopy bool) (map[any]any, error) {
+	merged := map[any]any{}
+
+	for index := range inputs {
+		current := inputs[index]
+
+		if len(current) == 0 {
+			continue
+		}
+
+		// Due to a bug in `mergo.Merge`
+		// (Note: in the `for` loop, it DOES modify the source of the previous loop iteration if it's a complex map and `mergo` gets a pointer to it,
+		// not only the destination of the current loop iteration),
+		// we don't give it our maps directly; we convert them to YAML strings and then back to `Go` maps,
+		// so `mergo` does not have access to the original pointers
+		yamlCurrent, err := yaml.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+
+		var dataCurrent map[any]any
+		if err = yaml.Unmarshal(yamlCurrent, &dataCurrent); err != nil {
+			return nil, err
+		}
+
+		var opts []func(*mergo.Config)
+		opts = append(opts, mergo.WithOverride, mergo.WithTypeCheck)
+
+		// This was fixed/broken in https://github.com/imdario/mergo/pull/231/files
+		// It was released in https://github.com/imdario/mergo/releases/tag/v0.3.14
+		// It was not working before in `github.com/imdario/mergo` so we need to disable it in our code
+		// opts = append(opts, mergo.WithOverwriteWithEmptyValue)
+
+		if appendSlice {
+			opts = append(opts, mergo.WithAppendSlice)
+		}
+
+		if sliceDeepCopy {
+			opts = append(opts, mergo.WithSliceDeepCopy)
+		}
+
+		if err = mergo.Merge(&merged, dataCurrent, opts...); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// legacyMergeWithListStrategies takes a list of maps of interface as input and returns a single map with the merged contents.
+// The lists are merged using the `listMergeStrategy` (`replace`, `append` or `merge`).
+// `listMergeStrategyOverrides` is a map of key names to list merge strategies, which override the default strategy for the lists with these keys
+func legacyMergeWithListStrategies(inputs []map[any]any, listMergeStrategy string, listMergeStrategyOverrides map[string]string) (map[any]any, error) {
+	if (listMergeStrategy == "" || listMergeStrategy == cfg.ListMergeStrategyReplace) && len(listMergeStrategyOverrides) == 0 {
+		return legacyMergeWithOptions(inputs, false, false)
+	}
+
+	merged := map[any]any{}
+
+	for index := range inputs {
+		current := inputs[index]
+
+		if len(current) == 0 {
+			continue
+		}
+
+		// Convert the map to a YAML string and then back to `Go` map, so `mergo` does not have access to the original pointers
+		// (see the comment in `legacyMergeWithOptions`)
+		yamlCurrent, err := yaml.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+
+		var dataCurrent map[any]any
+		if err = yaml.Unmarshal(yamlCurrent, &dataCurrent); err != nil {
+			return nil, err
+		}
+
+		// `mergo` replaces the lists in the destination with the lists from the source.
+		// Before deep-merging, replace the lists in the source with the lists combined using the list merge strategies
+		if err = legacyMergeLists(merged, dataCurrent, listMergeStrategy, listMergeStrategyOverrides); err != nil {
+			return nil, err
+		}
+
+		if err = mergo.Merge(&merged, dataCurrent, mergo.WithOverride, mergo.WithTypeCheck); err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// legacyMergeLists finds the lists that exist in both `dst` and `src` maps (at any level),
+// and replaces the lists in `src` with the lists combined using the list merge strategies
+func legacyMergeLists(dst map[any]any, src map[any]any, listMergeStrategy string, listMergeStrategyOverrides map[string]string) error {
+	for k, srcValue := range src {
+		dstValue, ok := dst[k]
+		if !ok {
+			continue
+		}
+
+		switch srcTyped := srcValue.(type) {
+		case map[any]any:
+			if dstTyped, ok := dstValue.(map[any]any); ok {
+				if err := legacyMergeLists(dstTyped, srcTyped, listMergeStrategy, listMergeStrategyOverrides); err != nil {
+					return err
+				}
+			}
+
+		case []any:
+			dstTyped, ok := dstValue.([]any)
+			if !ok {
+				continue
+			}
+
+			strategy := listMergeStrategy
+			if s, ok := listMergeStrategyOverrides[fmt.Sprintf("%v", k)]; ok {
+				strategy = s
+			}
+
+			switch strategy {
+			case cfg.ListMergeStrategyAppend:
+				list := make([]any, 0, len(dstTyped)+len(srcTyped))
+				list = append(list, dstTyped...)
+				list = append(list, srcTyped...)
+				src[k] = list
+
+			case cfg.ListMergeStrategyMerge:
+				list, err := legacyMergeListsByIndex(dstTyped, srcTyped, listMergeStrategy, listMergeStrategyOverrides)
+				if err != nil {
+					return err
+				}
+				src[k] = list
+
+			case "", cfg.ListMergeStrategyReplace:
+				// `mergo` replaces the list in the destination with the list from the source
+
+			default:
+				return fmt.Errorf("invalid list merge strategy '%s' for the key '%v'. Supported strategies: %s, %s, %s",
+					strategy, k, cfg.ListMergeStrategyReplace, cfg.ListMergeStrategyAppend, cfg.ListMergeStrategyMerge)
+			}
+		}
+	}
+
+	return nil
+}
+
+// legacyMergeListsByIndex deep-merges the items of the lists with the same index.
+// If the items are not maps, the item from the `src` list wins. The result has the length of the longest list
+func legacyMergeListsByIndex(dst []any, src []any, listMergeStrategy string, listMergeStrategyOverrides map[string]string) ([]any, error) {
+	length := len(dst)
+	if len(src) > length {
+		length = len(src)
+	}
+
+	list := make([]any, length)
+
+	for i := 0; i < length; i++ {
+		if i >= len(src) {
+			list[i] = dst[i]
+			continue
+		}
+		if i >= len(dst) {
+			list[i] = src[i]
+			continue
+		}
+
+		dstItem, dstIsMap := dst[i].(map[any]any)
+		srcItem, srcIsMap := src[i].(map[any]any)
+		if !dstIsMap || !srcIsMap {
+			list[i] = src[i]
+			continue
+		}
+
+		item, err := legacyMergeWithListStrategies([]map[any]any{dstItem, srcItem}, listMergeStrategy, listMergeStrategyOverrides)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = item
+	}
+
+	return list, nil
+}
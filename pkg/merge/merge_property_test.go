@@ -0,0 +1,174 @@
+package merge
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+)
+
+// The tests in this file check that the native deep-merge produces the same results as the previous implementation
+// (which converts the maps to YAML and back and uses `mergo`), using the stack config fixtures and randomly generated maps
+
+var propertyTestListMergeStrategies = []struct {
+	name      string
+	strategy  string
+	overrides map[string]string
+}{
+	{name: "replace", strategy: cfg.ListMergeStrategyReplace},
+	{name: "append", strategy: cfg.ListMergeStrategyAppend},
+	{name: "merge", strategy: cfg.ListMergeStrategyMerge},
+	{name: "overrides", strategy: cfg.ListMergeStrategyReplace, overrides: map[string]string{
+		"import":   cfg.ListMergeStrategyAppend,
+		"inherits": cfg.ListMergeStrategyAppend,
+		"c":        cfg.ListMergeStrategyMerge,
+		"d":        cfg.ListMergeStrategyAppend,
+	}},
+}
+
+// loadStackConfigFixtures parses all the stack config files in the `examples/complete/stacks` folder
+func loadStackConfigFixtures(t testing.TB) []map[any]any {
+	var files []string
+	err := filepath.Walk("../../examples/complete/stacks", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(p) == ".yaml" {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(files)
+
+	var fixtures []map[any]any
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var m map[any]any
+		if err = yaml.Unmarshal(content, &m); err != nil || len(m) == 0 {
+			continue
+		}
+		fixtures = append(fixtures, m)
+	}
+
+	if len(fixtures) == 0 {
+		t.Fatal("no stack config fixtures found")
+	}
+
+	return fixtures
+}
+
+func assertSameMergeResults(t *testing.T, inputs []map[any]any, strategy string, overrides map[string]string) {
+	expected, expectedErr := legacyMergeWithListStrategies(inputs, strategy, overrides)
+	result, err := MergeWithListStrategies(inputs, strategy, overrides)
+
+	if expectedErr != nil {
+		assert.NotNil(t, err, "the previous implementation returned the error '%v'", expectedErr)
+		return
+	}
+
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMergeSameResultsOnFixtures(t *testing.T) {
+	fixtures := loadStackConfigFixtures(t)
+
+	for _, s := range propertyTestListMergeStrategies {
+		t.Run(s.name, func(t *testing.T) {
+			// Deep-merge each fixture with itself and the next fixtures
+			for i := range fixtures {
+				for n := 1; n <= 3; n++ {
+					var inputs []map[any]any
+					for j := 0; j < n; j++ {
+						inputs = append(inputs, fixtures[(i+j)%len(fixtures)])
+					}
+					inputs = append(inputs, fixtures[i])
+
+					assertSameMergeResults(t, inputs, s.strategy, s.overrides)
+				}
+			}
+
+			// Deep-merge all fixtures
+			assertSameMergeResults(t, fixtures, s.strategy, s.overrides)
+		})
+	}
+}
+
+// randomValue generates a random value: a scalar, a map or a list
+func randomValue(r *rand.Rand, depth int) any {
+	n := 8
+	if depth > 0 {
+		n = 10
+	}
+
+	switch r.Intn(n) {
+	case 0:
+		return nil
+	case 1:
+		return ""
+	case 2:
+		return fmt.Sprintf("v%d", r.Intn(3))
+	case 3:
+		return r.Intn(2)
+	case 4:
+		return r.Intn(2) == 1
+	case 5:
+		return 1.5
+	case 6, 7:
+		return fmt.Sprintf("s%d", r.Intn(3))
+	case 8:
+		return randomMap(r, depth-1)
+	default:
+		l := make([]any, r.Intn(4))
+		for i := range l {
+			if r.Intn(2) == 0 {
+				l[i] = randomMap(r, depth-1)
+			} else {
+				l[i] = randomValue(r, 0)
+			}
+		}
+		return l
+	}
+}
+
+// randomMap generates a random map with the keys from a small set, so that the keys in different maps overlap
+func randomMap(r *rand.Rand, depth int) map[any]any {
+	keys := []string{"a", "b", "c", "d", "e"}
+	m := map[any]any{}
+	for i := r.Intn(len(keys) + 1); i > 0; i-- {
+		m[keys[r.Intn(len(keys))]] = randomValue(r, depth)
+	}
+	return m
+}
+
+func TestMergeSameResultsOnRandomMaps(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for _, s := range propertyTestListMergeStrategies {
+		t.Run(s.name, func(t *testing.T) {
+			for i := 0; i < 2000; i++ {
+				inputs := make([]map[any]any, 2+r.Intn(3))
+				for j := range inputs {
+					inputs[j] = randomMap(r, 3)
+				}
+
+				assertSameMergeResults(t, inputs, s.strategy, s.overrides)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
 )
 
 func TestMergeBasic(t *testing.T) {
@@ -13,7 +15,7 @@ func TestMergeBasic(t *testing.T) {
 	inputs := []map[any]any{map1, map2}
 	expected := map[any]any{"foo": "bar", "baz": "bat"}
 
-	result, err := Merge(inputs)
+	result, err := Merge(cfg.CliConfiguration{}, inputs)
 	assert.Nil(t, err)
 	assert.Equal(t, expected, result)
 }
@@ -26,7 +28,171 @@ func TestMergeBasicOverride(t *testing.T) {
 	inputs := []map[any]any{map1, map2, map3}
 	expected := map[any]any{"foo": "ood", "baz": "bat"}
 
-	result, err := Merge(inputs)
+	result, err := Merge(cfg.CliConfiguration{}, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMergeListReplace(t *testing.T) {
+	map1 := map[any]any{"list": []any{"1", "2"}}
+	map2 := map[any]any{"list": []any{"3"}}
+
+	inputs := []map[any]any{map1, map2}
+	expected := map[any]any{"list": []any{"3"}}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: cfg.ListMergeStrategyReplace}}
+
+	result, err := Merge(cliConfig, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMergeListAppend(t *testing.T) {
+	map1 := map[any]any{"foo": map[any]any{"list": []any{"1", "2"}}}
+	map2 := map[any]any{"foo": map[any]any{"list": []any{"3"}}}
+	map3 := map[any]any{"foo": map[any]any{"list": []any{"4"}}}
+
+	inputs := []map[any]any{map1, map2, map3}
+	expected := map[any]any{"foo": map[any]any{"list": []any{"1", "2", "3", "4"}}}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: cfg.ListMergeStrategyAppend}}
+
+	result, err := Merge(cliConfig, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMergeListMerge(t *testing.T) {
+	map1 := map[any]any{"list": []any{
+		map[any]any{"a": 1, "b": 2},
+		map[any]any{"c": 3},
+		"x",
+	}}
+	map2 := map[any]any{"list": []any{
+		map[any]any{"b": 20},
+		"y",
+	}}
+
+	inputs := []map[any]any{map1, map2}
+	expected := map[any]any{"list": []any{
+		map[any]any{"a": 1, "b": 20},
+		"y",
+		"x",
+	}}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: cfg.ListMergeStrategyMerge}}
+
+	result, err := Merge(cliConfig, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMergeListStrategyOverrides(t *testing.T) {
+	map1 := map[any]any{"allowed_cidrs": []any{"10.0.0.0/16"}, "zones": []any{"a", "b"}}
+	map2 := map[any]any{"allowed_cidrs": []any{"10.1.0.0/16"}, "zones": []any{"c"}}
+
+	inputs := []map[any]any{map1, map2}
+	expected := map[any]any{"allowed_cidrs": []any{"10.0.0.0/16", "10.1.0.0/16"}, "zones": []any{"c"}}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{
+		ListMergeStrategyOverrides: map[string]string{"allowed_cidrs": cfg.ListMergeStrategyAppend},
+	}}
+
+	result, err := Merge(cliConfig, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMergeListStrategyOverridesNestedKeys(t *testing.T) {
+	// The overrides match the key name at any level of the maps, including the maps in the lists
+	map1 := map[any]any{
+		"vars": map[any]any{
+			"allowed_cidrs": []any{"10.0.0.0/16"},
+			"security_groups": []any{
+				map[any]any{"name": "sg", "allowed_cidrs": []any{"10.2.0.0/16"}},
+			},
+		},
+	}
+	map2 := map[any]any{
+		"vars": map[any]any{
+			"allowed_cidrs": []any{"10.1.0.0/16"},
+			"security_groups": []any{
+				map[any]any{"allowed_cidrs": []any{"10.3.0.0/16"}},
+			},
+		},
+	}
+
+	inputs := []map[any]any{map1, map2}
+	expected := map[any]any{
+		"vars": map[any]any{
+			"allowed_cidrs": []any{"10.0.0.0/16", "10.1.0.0/16"},
+			"security_groups": []any{
+				map[any]any{"name": "sg", "allowed_cidrs": []any{"10.2.0.0/16", "10.3.0.0/16"}},
+			},
+		},
+	}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{
+		ListMergeStrategy: cfg.ListMergeStrategyReplace,
+		ListMergeStrategyOverrides: map[string]string{
+			"allowed_cidrs":   cfg.ListMergeStrategyAppend,
+			"security_groups": cfg.ListMergeStrategyMerge,
+		},
+	}}
+
+	result, err := Merge(cliConfig, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+
+	// The dotted paths are not supported, the key `vars.allowed_cidrs` does not match any key
+	cliConfig.Settings.ListMergeStrategyOverrides = map[string]string{"vars.allowed_cidrs": cfg.ListMergeStrategyAppend}
+
+	result, err = Merge(cliConfig, inputs)
+	assert.Nil(t, err)
+	assert.Equal(t, []any{"10.1.0.0/16"}, result["vars"].(map[any]any)["allowed_cidrs"])
+}
+
+func TestMergeListInvalidStrategy(t *testing.T) {
+	map1 := map[any]any{"list": []any{"1"}}
+	map2 := map[any]any{"list": []any{"2"}}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: "invalid"}}
+
+	_, err := Merge(cliConfig, []map[any]any{map1, map2})
+	assert.NotNil(t, err)
+}
+
+func TestMergeDoesNotModifyInputs(t *testing.T) {
+	map1 := map[any]any{"foo": map[any]any{"a": 1, "list": []any{map[any]any{"b": 2}}}}
+	map2 := map[any]any{"foo": map[any]any{"a": 10, "list": []any{map[any]any{"c": 3}}}}
+
+	inputs := []map[any]any{map1, map2}
+
+	for _, strategy := range []string{cfg.ListMergeStrategyReplace, cfg.ListMergeStrategyAppend, cfg.ListMergeStrategyMerge} {
+		cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: strategy}}
+
+		result, err := Merge(cliConfig, inputs)
+		assert.Nil(t, err)
+
+		// Modify the result and check that the inputs are not modified
+		result["foo"].(map[any]any)["a"] = 100
+		result["foo"].(map[any]any)["list"].([]any)[0].(map[any]any)["d"] = 4
+
+		assert.Equal(t, map[any]any{"foo": map[any]any{"a": 1, "list": []any{map[any]any{"b": 2}}}}, map1)
+		assert.Equal(t, map[any]any{"foo": map[any]any{"a": 10, "list": []any{map[any]any{"c": 3}}}}, map2)
+	}
+}
+
+func TestMergeNormalizesTypes(t *testing.T) {
+	map1 := map[any]any{"foo": map[string]any{"a": 1, "list": []string{"x"}}}
+	map2 := map[any]any{"foo": map[any]any{"b": 2, "list": []any{"y"}}}
+
+	inputs := []map[any]any{map1, map2}
+	expected := map[any]any{"foo": map[any]any{"a": 1, "b": 2, "list": []any{"x", "y"}}}
+
+	cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: cfg.ListMergeStrategyAppend}}
+
+	result, err := Merge(cliConfig, inputs)
 	assert.Nil(t, err)
 	assert.Equal(t, expected, result)
 }
@@ -24,7 +24,9 @@ func CreateSpaceliftStacks(
 	stackConfigPathTemplate string) (map[string]any, error) {
 
 	if len(filePaths) > 0 {
+		// The CLI config is not loaded when the paths are provided, so the default settings (e.g. the list merge strategy) are used
 		_, stacks, _, err := s.ProcessYAMLConfigFiles(
+			cfg.CliConfiguration{},
 			stacksBasePath,
 			terraformComponentsBasePath,
 			helmfileComponentsBasePath,
@@ -47,6 +49,7 @@ func CreateSpaceliftStacks(
 		}
 
 		_, stacks, _, err := s.ProcessYAMLConfigFiles(
+			cliConfig,
 			cliConfig.StacksBaseAbsolutePath,
 			cliConfig.TerraformDirAbsolutePath,
 			cliConfig.HelmfileDirAbsolutePath,
@@ -1,6 +1,8 @@
 package stack
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"path"
 	"path/filepath"
@@ -12,7 +14,7 @@ import (
 	c "github.com/cloudposse/atmos/pkg/convert"
 	m "github.com/cloudposse/atmos/pkg/merge"
 	u "github.com/cloudposse/atmos/pkg/utils"
-	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v2"
 )
 
@@ -22,8 +24,12 @@ var (
 )
 
 // ProcessYAMLConfigFiles takes a list of paths to stack config files, processes and deep-merges all imports,
-// and returns a list of stack configs
+// and returns a list of stack configs.
+// The files are processed concurrently by at most `stacks.max_concurrency` goroutines.
+// After the first error, the files that have not been started yet are not processed,
+// and the errors from all the processed files are returned as one error
 func ProcessYAMLConfigFiles(
+	cliConfig cfg.CliConfiguration,
 	stacksBasePath string,
 	terraformComponentsBasePath string,
 	helmfileComponentsBasePath string,
@@ -42,14 +48,20 @@ func ProcessYAMLConfigFiles(
 	listResult := make([]string, count)
 	mapResult := map[string]any{}
 	rawStackConfigs := map[string]map[string]any{}
-	var errorResult error
-	var wg sync.WaitGroup
-	wg.Add(count)
+	errorResults := make([]error, count)
+
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(stacksMaxConcurrency(cliConfig))
 
 	for i, filePath := range filePaths {
-		go func(i int, p string) {
-			defer wg.Done()
+		// Stop scheduling the files after the first error
+		if ctx.Err() != nil {
+			break
+		}
 
+		i, p := i, filePath
+
+		g.Go(func() error {
 			stackBasePath := stacksBasePath
 			if len(stackBasePath) < 1 {
 				stackBasePath = path.Dir(p)
@@ -63,6 +75,7 @@ func ProcessYAMLConfigFiles(
 			)
 
 			deepMergedStackConfig, importsConfig, stackConfig, err := ProcessYAMLConfigFile(
+				cliConfig,
 				stackBasePath,
 				p,
 				map[string]map[any]any{},
@@ -71,8 +84,8 @@ func ProcessYAMLConfigFiles(
 			)
 
 			if err != nil {
-				errorResult = err
-				return
+				errorResults[i] = stackConfigFileError(stackBasePath, p, err)
+				return errorResults[i]
 			}
 
 			var imports []string
@@ -88,14 +101,15 @@ func ProcessYAMLConfigFiles(
 			//if processStackDeps {
 			//	componentStackMap, err = CreateComponentStackMap(stackBasePath, p)
 			//	if err != nil {
-			//		errorResult = err
-			//		return
+			//		errorResults[i] = err
+			//		return nil
 			//	}
 			//}
 
 			componentStackMap := map[string]map[string][]string{}
 
 			finalConfig, err := ProcessStackConfig(
+				cliConfig,
 				stackBasePath,
 				terraformComponentsBasePath,
 				helmfileComponentsBasePath,
@@ -108,16 +122,16 @@ func ProcessYAMLConfigFiles(
 				importsConfig,
 				true)
 			if err != nil {
-				errorResult = err
-				return
+				errorResults[i] = stackConfigFileError(stackBasePath, p, err)
+				return errorResults[i]
 			}
 
 			finalConfig["imports"] = uniqueImports
 
 			yamlConfig, err := yaml.Marshal(finalConfig)
 			if err != nil {
-				errorResult = err
-				return
+				errorResults[i] = stackConfigFileError(stackBasePath, p, err)
+				return errorResults[i]
 			}
 
 			processYAMLConfigFilesLock.Lock()
@@ -128,13 +142,16 @@ func ProcessYAMLConfigFiles(
 			rawStackConfigs[stackFileName] = map[string]any{}
 			rawStackConfigs[stackFileName]["stack"] = stackConfig
 			rawStackConfigs[stackFileName]["imports"] = importsConfig
-		}(i, filePath)
+			return nil
+		})
 	}
 
-	wg.Wait()
+	// The first error cancels the context. The errors from all the processed files are collected in `errorResults`
+	_ = g.Wait()
 
-	if errorResult != nil {
-		return nil, nil, nil, errorResult
+	// Return the errors in the order of the files
+	if err := errors.Join(errorResults...); err != nil {
+		return nil, nil, nil, err
 	}
 
 	return listResult, mapResult, rawStackConfigs, nil
@@ -144,11 +161,34 @@ func ProcessYAMLConfigFiles(
 // recursively processes and deep-merges all imports,
 // and returns the final stack config
 func ProcessYAMLConfigFile(
+	cliConfig cfg.CliConfiguration,
+	basePath string,
+	filePath string,
+	importsConfig map[string]map[any]any,
+	context map[string]any,
+	ignoreMissingFiles bool,
+) (
+	map[any]any,
+	map[string]map[any]any,
+	map[any]any,
+	error,
+) {
+	return ProcessYAMLConfigFileWithProvenance(cliConfig, basePath, filePath, importsConfig, context, ignoreMissingFiles, nil)
+}
+
+// ProcessYAMLConfigFileWithProvenance does the same as `ProcessYAMLConfigFile`,
+// and if `provenance` is not nil, records the files and lines where all the values in the stack config and the imports are set,
+// in the order of deep-merging.
+// Each imported file is deep-merged only once (if it's imported more than once with the same context, e.g. by two files
+// that import the same file, only the first import is used), so the lists from the file are not appended twice
+func ProcessYAMLConfigFileWithProvenance(
+	cliConfig cfg.CliConfiguration,
 	basePath string,
 	filePath string,
 	importsConfig map[string]map[any]any,
 	context map[string]any,
 	ignoreMissingFiles bool,
+	provenance cfg.Provenance,
 ) (
 	map[any]any,
 	map[string]map[any]any,
@@ -156,7 +196,68 @@ func ProcessYAMLConfigFile(
 	error,
 ) {
 
+	layers, stackConfigMap, err := processYAMLConfigFileLayers(cliConfig, basePath, filePath, importsConfig, context, ignoreMissingFiles)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	var stackConfigs []map[any]any
+
+	for _, layer := range layers {
+		stackConfigs = append(stackConfigs, layer.config)
+
+		if provenance != nil {
+			if err = recordProvenance(provenance, layer.file, layer.content); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	// Deep-merge the stack config file and all the imports
+	stackConfigsDeepMerged, err := m.Merge(cliConfig, stackConfigs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return stackConfigsDeepMerged, importsConfig, stackConfigMap, nil
+}
+
+// stackConfigLayer is the config of a stack config file (the file itself or one of its imports),
+// in the list of the configs deep-merged into the stack config
+type stackConfigLayer struct {
+	// The file path and the Go template context of the file, which identify the layer
+	key string
+	// The file path relative to the stacks base path
+	file string
+	// The content of the file after processing the Go templates
+	content string
+	config  map[any]any
+}
+
+// stackConfigLayerKey returns the key of the stack config file processed with the context.
+// `fmt` prints the maps with sorted keys, so the same context always produces the same key
+func stackConfigLayerKey(filePath string, context map[string]any) string {
+	return fmt.Sprintf("%s:%v", filePath, context)
+}
+
+// processYAMLConfigFileLayers reads the stack config file and recursively processes all its imports.
+// It returns the configs of all the imports (each import only once, in the order of deep-merging) followed by the config of the file,
+// and the raw config of the file. The raw configs of all the imports are added to `importsConfig`
+func processYAMLConfigFileLayers(
+	cliConfig cfg.CliConfiguration,
+	basePath string,
+	filePath string,
+	importsConfig map[string]map[any]any,
+	context map[string]any,
+	ignoreMissingFiles bool,
+) (
+	[]stackConfigLayer,
+	map[any]any,
+	error,
+) {
+
+	var layers []stackConfigLayer
+	visited := map[string]bool{}
 	relativeFilePath := u.TrimBasePathFromPath(basePath+"/", filePath)
 
 	stackYamlConfig, err := getFileContent(filePath)
@@ -166,34 +267,34 @@ func ProcessYAMLConfigFile(
 	// If we add a new stack config file with some component configurations to the current branch, then the new file will not be present in
 	// the remote branch (with which the current branch is compared), and `atmos` would throw an error.
 	if err != nil && !ignoreMissingFiles {
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
 	// Process `Go` templates in the stack config file using the provided context
 	if len(context) > 0 {
 		stackYamlConfig, err = u.ProcessTmpl(relativeFilePath, stackYamlConfig, context)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
 	}
 
 	stackConfigMap, err := c.YAMLToMapOfInterfaces(stackYamlConfig)
 	if err != nil {
 		e := fmt.Errorf("invalid stack config file '%s'\n%v", relativeFilePath, err)
-		return nil, nil, nil, e
+		return nil, nil, e
 	}
 
 	// Find and process all imports
 	importStructs, err := processImportSection(stackConfigMap, relativeFilePath)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, err
 	}
 
 	for _, importStruct := range importStructs {
 		imp := importStruct.Path
 
 		if imp == "" {
-			return nil, nil, nil, fmt.Errorf("invalid empty import in the file '%s'", relativeFilePath)
+			return nil, nil, fmt.Errorf("invalid empty import in the file '%s'", relativeFilePath)
 		}
 
 		// If the import file is specified without extension, use `.yaml` as default
@@ -210,7 +311,7 @@ func ProcessYAMLConfigFile(
 			errorMessage := fmt.Sprintf("invalid import in the file '%s'\nThe file imports itself in '%s'",
 				relativeFilePath,
 				imp)
-			return nil, nil, nil, errors.New(errorMessage)
+			return nil, nil, errors.New(errorMessage)
 		}
 
 		// Find all import matches in the glob
@@ -224,12 +325,12 @@ func ProcessYAMLConfigFile(
 					imp,
 					relativeFilePath,
 					err)
-				return nil, nil, nil, errors.New(errorMessage)
+				return nil, nil, errors.New(errorMessage)
 			} else if importMatches == nil {
 				errorMessage := fmt.Sprintf("invalid import in the file '%s'\nNo matches found for the import '%s'",
 					relativeFilePath,
 					imp)
-				return nil, nil, nil, errors.New(errorMessage)
+				return nil, nil, errors.New(errorMessage)
 			}
 		}
 
@@ -238,24 +339,34 @@ func ProcessYAMLConfigFile(
 		// The current `context` takes precedence over the parent `context` and will override items with the same keys.
 		// TODO: instead of calling the conversion functions, we need to switch to generics and update everything to support it
 		listOfMaps := []map[any]any{c.MapsOfStringsToMapsOfInterfaces(context), c.MapsOfStringsToMapsOfInterfaces(importStruct.Context)}
-		mergedContext, err := m.Merge(listOfMaps)
+		mergedContext, err := m.Merge(cliConfig, listOfMaps)
 		if err != nil {
-			return nil, nil, nil, err
+			return nil, nil, err
 		}
 
 		for _, importFile := range importMatches {
-			yamlConfig, _, yamlConfigRaw, err := ProcessYAMLConfigFile(
+			importLayers, yamlImportsConfig, yamlConfigRaw, err := processYAMLConfigImportFile(
+				cliConfig,
 				basePath,
 				importFile,
-				importsConfig,
 				c.MapsOfInterfacesToMapsOfStrings(mergedContext),
 				ignoreMissingFiles,
 			)
 			if err != nil {
-				return nil, nil, nil, err
+				return nil, nil, err
+			}
+
+			// Skip the files already imported with the same context (e.g. by another import)
+			for _, layer := range importLayers {
+				if !visited[layer.key] {
+					visited[layer.key] = true
+					layers = append(layers, layer)
+				}
 			}
 
-			stackConfigs = append(stackConfigs, yamlConfig)
+			for k, v := range yamlImportsConfig {
+				importsConfig[k] = v
+			}
 			importRelativePathWithExt := strings.Replace(importFile, basePath+"/", "", 1)
 			ext2 := filepath.Ext(importRelativePathWithExt)
 			if ext2 == "" {
@@ -267,21 +378,21 @@ func ProcessYAMLConfigFile(
 	}
 
 	if len(stackConfigMap) > 0 {
-		stackConfigs = append(stackConfigs, stackConfigMap)
-	}
-
-	// Deep-merge the stack config file and all the imports
-	stackConfigsDeepMerged, err := m.Merge(stackConfigs)
-	if err != nil {
-		return nil, nil, nil, err
+		layers = append(layers, stackConfigLayer{
+			key:     stackConfigLayerKey(filePath, context),
+			file:    relativeFilePath,
+			content: stackYamlConfig,
+			config:  stackConfigMap,
+		})
 	}
 
-	return stackConfigsDeepMerged, importsConfig, stackConfigMap, nil
+	return layers, stackConfigMap, nil
 }
 
 // ProcessStackConfig takes a raw stack config, deep-merges all variables, settings, environments and backends,
 // and returns the final stack configuration for all Terraform and helmfile components
 func ProcessStackConfig(
+	cliConfig cfg.CliConfiguration,
 	stacksBasePath string,
 	terraformComponentsBasePath string,
 	helmfileComponentsBasePath string,
@@ -372,7 +483,7 @@ func ProcessStackConfig(
 		}
 	}
 
-	globalAndTerraformVars, err := m.Merge([]map[any]any{globalVarsSection, terraformVars})
+	globalAndTerraformVars, err := m.Merge(cliConfig, []map[any]any{globalVarsSection, terraformVars})
 	if err != nil {
 		return nil, err
 	}
@@ -384,7 +495,7 @@ func ProcessStackConfig(
 		}
 	}
 
-	globalAndTerraformSettings, err := m.Merge([]map[any]any{globalSettingsSection, terraformSettings})
+	globalAndTerraformSettings, err := m.Merge(cliConfig, []map[any]any{globalSettingsSection, terraformSettings})
 	if err != nil {
 		return nil, err
 	}
@@ -396,7 +507,7 @@ func ProcessStackConfig(
 		}
 	}
 
-	globalAndTerraformEnv, err := m.Merge([]map[any]any{globalEnvSection, terraformEnv})
+	globalAndTerraformEnv, err := m.Merge(cliConfig, []map[any]any{globalEnvSection, terraformEnv})
 	if err != nil {
 		return nil, err
 	}
@@ -445,7 +556,7 @@ func ProcessStackConfig(
 		}
 	}
 
-	globalAndHelmfileVars, err := m.Merge([]map[any]any{globalVarsSection, helmfileVars})
+	globalAndHelmfileVars, err := m.Merge(cliConfig, []map[any]any{globalVarsSection, helmfileVars})
 	if err != nil {
 		return nil, err
 	}
@@ -457,7 +568,7 @@ func ProcessStackConfig(
 		}
 	}
 
-	globalAndHelmfileSettings, err := m.Merge([]map[any]any{globalSettingsSection, helmfileSettings})
+	globalAndHelmfileSettings, err := m.Merge(cliConfig, []map[any]any{globalSettingsSection, helmfileSettings})
 	if err != nil {
 		return nil, err
 	}
@@ -469,7 +580,7 @@ func ProcessStackConfig(
 		}
 	}
 
-	globalAndHelmfileEnv, err := m.Merge([]map[any]any{globalEnvSection, helmfileEnv})
+	globalAndHelmfileEnv, err := m.Merge(cliConfig, []map[any]any{globalEnvSection, helmfileEnv})
 	if err != nil {
 		return nil, err
 	}
@@ -599,6 +710,7 @@ func ProcessStackConfig(
 
 					// Process the base components recursively to find `componentInheritanceChain`
 					err = ProcessBaseComponentConfig(
+						cliConfig,
 						&baseComponentConfig,
 						allTerraformComponentsMap,
 						component,
@@ -665,6 +777,7 @@ func ProcessStackConfig(
 
 						// Process the baseComponentFromInheritList components recursively to find `componentInheritanceChain`
 						err = ProcessBaseComponentConfig(
+							cliConfig,
 							&baseComponentConfig,
 							allTerraformComponentsMap,
 							component,
@@ -693,17 +806,17 @@ func ProcessStackConfig(
 				baseComponents = u.UniqueStrings(baseComponents)
 				sort.Strings(baseComponents)
 
-				finalComponentVars, err := m.Merge([]map[any]any{globalAndTerraformVars, baseComponentVars, componentVars})
+				finalComponentVars, err := m.Merge(cliConfig, []map[any]any{globalAndTerraformVars, baseComponentVars, componentVars})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentSettings, err := m.Merge([]map[any]any{globalAndTerraformSettings, baseComponentSettings, componentSettings})
+				finalComponentSettings, err := m.Merge(cliConfig, []map[any]any{globalAndTerraformSettings, baseComponentSettings, componentSettings})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentEnv, err := m.Merge([]map[any]any{globalAndTerraformEnv, baseComponentEnv, componentEnv})
+				finalComponentEnv, err := m.Merge(cliConfig, []map[any]any{globalAndTerraformEnv, baseComponentEnv, componentEnv})
 				if err != nil {
 					return nil, err
 				}
@@ -717,7 +830,7 @@ func ProcessStackConfig(
 					finalComponentBackendType = componentBackendType
 				}
 
-				finalComponentBackendSection, err := m.Merge([]map[any]any{globalBackendSection,
+				finalComponentBackendSection, err := m.Merge(cliConfig, []map[any]any{globalBackendSection,
 					baseComponentBackendSection,
 					componentBackendSection})
 				if err != nil {
@@ -778,7 +891,7 @@ func ProcessStackConfig(
 					finalComponentRemoteStateBackendType = componentRemoteStateBackendType
 				}
 
-				finalComponentRemoteStateBackendSection, err := m.Merge([]map[any]any{globalRemoteStateBackendSection,
+				finalComponentRemoteStateBackendSection, err := m.Merge(cliConfig, []map[any]any{globalRemoteStateBackendSection,
 					baseComponentRemoteStateBackendSection,
 					componentRemoteStateBackendSection})
 				if err != nil {
@@ -787,7 +900,7 @@ func ProcessStackConfig(
 
 				// Merge `backend` and `remote_state_backend` sections
 				// This will allow keeping `remote_state_backend` section DRY
-				finalComponentRemoteStateBackendSectionMerged, err := m.Merge([]map[any]any{finalComponentBackendSection,
+				finalComponentRemoteStateBackendSectionMerged, err := m.Merge(cliConfig, []map[any]any{finalComponentBackendSection,
 					finalComponentRemoteStateBackendSection})
 				if err != nil {
 					return nil, err
@@ -953,6 +1066,7 @@ func ProcessStackConfig(
 
 					// Process the base components recursively to find `componentInheritanceChain`
 					err = ProcessBaseComponentConfig(
+						cliConfig,
 						&baseComponentConfig,
 						allHelmfileComponentsMap,
 						component,
@@ -1015,6 +1129,7 @@ func ProcessStackConfig(
 
 						// Process the baseComponentFromInheritList components recursively to find `componentInheritanceChain`
 						err = ProcessBaseComponentConfig(
+							cliConfig,
 							&baseComponentConfig,
 							allHelmfileComponentsMap,
 							component,
@@ -1037,17 +1152,17 @@ func ProcessStackConfig(
 					}
 				}
 
-				finalComponentVars, err := m.Merge([]map[any]any{globalAndHelmfileVars, baseComponentVars, componentVars})
+				finalComponentVars, err := m.Merge(cliConfig, []map[any]any{globalAndHelmfileVars, baseComponentVars, componentVars})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentSettings, err := m.Merge([]map[any]any{globalAndHelmfileSettings, baseComponentSettings, componentSettings})
+				finalComponentSettings, err := m.Merge(cliConfig, []map[any]any{globalAndHelmfileSettings, baseComponentSettings, componentSettings})
 				if err != nil {
 					return nil, err
 				}
 
-				finalComponentEnv, err := m.Merge([]map[any]any{globalAndHelmfileEnv, baseComponentEnv, componentEnv})
+				finalComponentEnv, err := m.Merge(cliConfig, []map[any]any{globalAndHelmfileEnv, baseComponentEnv, componentEnv})
 				if err != nil {
 					return nil, err
 				}
@@ -0,0 +1,126 @@
+package stack
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+	m "github.com/cloudposse/atmos/pkg/merge"
+)
+
+var (
+	// Cache of the processed imported stack config files, shared between all the stacks processed by `ProcessYAMLConfigFiles`.
+	// The same catalog and mixin files are imported into many stacks, and each import is read, templated and parsed
+	// with its own imports only once for the same context.
+	// The files are not re-read while they are in the cache, so `ResetStackConfigCaches` must be called after the files change
+	importFileCacheSyncMap = sync.Map{}
+)
+
+// ResetStackConfigCaches removes the contents of the stack config files and the processed imported stack config files from the caches
+func ResetStackConfigCaches() {
+	for _, cache := range []*sync.Map{&getFileContentSyncMap, &importFileCacheSyncMap} {
+		cache.Range(func(key, _ any) bool {
+			cache.Delete(key)
+			return true
+		})
+	}
+}
+
+// importFileCacheEntry holds the result of processing an imported stack config file
+type importFileCacheEntry struct {
+	once          sync.Once
+	layers        []stackConfigLayer
+	importsConfig map[string]map[any]any
+	rawConfig     map[any]any
+	err           error
+}
+
+// importFileCacheKey returns the cache key of the imported stack config file.
+// The result of processing the file depends on the Go template context and on the deep-merge settings in the CLI config
+func importFileCacheKey(
+	cliConfig cfg.CliConfiguration,
+	basePath string,
+	filePath string,
+	context map[string]any,
+	ignoreMissingFiles bool,
+) (string, error) {
+
+	key := struct {
+		BasePath           string          `json:"base_path"`
+		FilePath           string          `json:"file_path"`
+		Context            map[string]any  `json:"context"`
+		IgnoreMissingFiles bool            `json:"ignore_missing_files"`
+		Settings           cfg.CliSettings `json:"settings"`
+	}{
+		BasePath:           basePath,
+		FilePath:           filePath,
+		Context:            context,
+		IgnoreMissingFiles: ignoreMissingFiles,
+		Settings:           cliConfig.Settings,
+	}
+
+	// `json.Marshal` sorts the map keys, so the same context always produces the same key
+	b, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// processYAMLConfigImportFile processes the imported stack config file and all its imports.
+// It returns the configs of the file and all its imports in the order of deep-merging, the raw configs of all its imports,
+// and the raw config of the file.
+// The results are cached and shared between the stacks. The callers get deep copies of the raw configs, so they can modify them.
+// The configs in the returned layers are shared, and they are only read when deep-merging the stack configs
+func processYAMLConfigImportFile(
+	cliConfig cfg.CliConfiguration,
+	basePath string,
+	filePath string,
+	context map[string]any,
+	ignoreMissingFiles bool,
+) (
+	[]stackConfigLayer,
+	map[string]map[any]any,
+	map[any]any,
+	error,
+) {
+
+	process := func() ([]stackConfigLayer, map[string]map[any]any, map[any]any, error) {
+		importsConfig := map[string]map[any]any{}
+		layers, rawConfig, err := processYAMLConfigFileLayers(cliConfig, basePath, filePath, importsConfig, context, ignoreMissingFiles)
+		return layers, importsConfig, rawConfig, err
+	}
+
+	key, err := importFileCacheKey(cliConfig, basePath, filePath, context, ignoreMissingFiles)
+	if err != nil {
+		// The context can't be used as a cache key, process the file without the cache
+		return process()
+	}
+
+	v, _ := importFileCacheSyncMap.LoadOrStore(key, &importFileCacheEntry{})
+	entry := v.(*importFileCacheEntry)
+
+	entry.once.Do(func() {
+		entry.layers, entry.importsConfig, entry.rawConfig, entry.err = process()
+
+		// Don't keep the errors in the cache, so the file is processed again the next time
+		if entry.err != nil {
+			importFileCacheSyncMap.Delete(key)
+		}
+	})
+
+	if entry.err != nil {
+		return nil, nil, nil, entry.err
+	}
+
+	importsConfig := make(map[string]map[any]any, len(entry.importsConfig))
+	for k, v := range entry.importsConfig {
+		importsConfig[k] = m.DeepCopy(v)
+	}
+
+	return entry.layers, importsConfig, m.DeepCopy(entry.rawConfig), nil
+}
@@ -1,11 +1,16 @@
 package stack
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
 	c "github.com/cloudposse/atmos/pkg/convert"
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
-	"testing"
 )
 
 func TestStackProcessor(t *testing.T) {
@@ -24,6 +29,7 @@ func TestStackProcessor(t *testing.T) {
 	processComponentDeps := true
 
 	var listResult, mapResult, _, err = ProcessYAMLConfigFiles(
+		cfg.CliConfiguration{},
 		stacksBasePath,
 		terraformComponentsBasePath,
 		helmfileComponentsBasePath,
@@ -203,3 +209,277 @@ func TestStackProcessor(t *testing.T) {
 	assert.Nil(t, err)
 	t.Log(string(yamlConfig))
 }
+
+func TestStackProcessorImportCache(t *testing.T) {
+	ResetStackConfigCaches()
+	t.Cleanup(ResetStackConfigCaches)
+
+	stacksBasePath := "../../examples/complete/stacks"
+	filePath := "../../examples/complete/stacks/orgs/cp/tenant1/dev/us-east-2.yaml"
+
+	// Process the stack config file twice, the second time the imports are taken from the cache
+	config1, importsConfig1, _, err := ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+
+	config2, importsConfig2, _, err := ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+
+	// Process the stack config file with provenance, which merges the same cached imports
+	config3, importsConfig3, _, err := ProcessYAMLConfigFileWithProvenance(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false, cfg.Provenance{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, config3, config1)
+	assert.Equal(t, config3, config2)
+	assert.Equal(t, importsConfig3, importsConfig1)
+	assert.Equal(t, importsConfig3, importsConfig2)
+
+	cachedImports := 0
+	importFileCacheSyncMap.Range(func(_, _ any) bool {
+		cachedImports++
+		return true
+	})
+	assert.Greater(t, cachedImports, 0)
+}
+
+func TestStackProcessorImportCacheReturnsCopies(t *testing.T) {
+	ResetStackConfigCaches()
+	t.Cleanup(ResetStackConfigCaches)
+
+	stacksBasePath := "../../examples/complete/stacks"
+	importPath := "../../examples/complete/stacks/catalog/terraform/test-component.yaml"
+	filePath := "../../examples/complete/stacks/orgs/cp/tenant1/dev/us-east-2.yaml"
+
+	_, importsConfig1, rawConfig1, err := processYAMLConfigImportFile(cfg.CliConfiguration{}, stacksBasePath, importPath, nil, false)
+	assert.Nil(t, err)
+
+	config1, _, _, err := ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+
+	// Modify the returned maps, the cached results must not change
+	rawConfig1["components"].(map[any]any)["terraform"] = "modified"
+	assert.Greater(t, len(importsConfig1), 0)
+	for k := range importsConfig1 {
+		importsConfig1[k]["vars"] = "modified"
+	}
+	importsConfig1["modified"] = map[any]any{}
+	config1["components"].(map[any]any)["terraform"] = "modified"
+
+	_, importsConfig2, rawConfig2, err := processYAMLConfigImportFile(cfg.CliConfiguration{}, stacksBasePath, importPath, nil, false)
+	assert.Nil(t, err)
+
+	assert.IsType(t, map[any]any{}, rawConfig2["components"].(map[any]any)["terraform"])
+	assert.NotContains(t, importsConfig2, "modified")
+	for _, v := range importsConfig2 {
+		assert.NotEqual(t, "modified", v["vars"])
+	}
+
+	config2, _, _, err := ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+	assert.IsType(t, map[any]any{}, config2["components"].(map[any]any)["terraform"])
+}
+
+func TestStackProcessorResetStackConfigCaches(t *testing.T) {
+	ResetStackConfigCaches()
+	t.Cleanup(ResetStackConfigCaches)
+
+	stacksBasePath := t.TempDir()
+	filePath := filepath.Join(stacksBasePath, "dev.yaml")
+	importPath := filepath.Join(stacksBasePath, "catalog.yaml")
+
+	err := os.WriteFile(filePath, []byte("import:\n  - catalog\n"), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(importPath, []byte("vars:\n  stage: dev\n"), 0644)
+	assert.Nil(t, err)
+
+	config, _, _, err := ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", config["vars"].(map[any]any)["stage"])
+
+	err = os.WriteFile(importPath, []byte("vars:\n  stage: prod\n"), 0644)
+	assert.Nil(t, err)
+
+	// The imported file is taken from the cache until the cache is reset
+	config, _, _, err = ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "dev", config["vars"].(map[any]any)["stage"])
+
+	ResetStackConfigCaches()
+
+	config, _, _, err = ProcessYAMLConfigFile(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "prod", config["vars"].(map[any]any)["stage"])
+}
+
+func TestStackProcessorReportsAllErrors(t *testing.T) {
+	stacksBasePath := t.TempDir()
+
+	files := map[string]string{
+		"good.yaml": `
+vars:
+  stage: good
+`,
+		"broken-1.yaml": `
+vars:
+  stage: [broken
+`,
+		"broken-2.yaml": `
+import:
+  - catalog/missing
+`,
+	}
+
+	var filePaths []string
+	for _, name := range []string{"broken-1.yaml", "good.yaml", "broken-2.yaml"} {
+		p := filepath.Join(stacksBasePath, name)
+		err := os.WriteFile(p, []byte(files[name]), 0644)
+		assert.Nil(t, err)
+		filePaths = append(filePaths, p)
+	}
+
+	// All the files are processed concurrently
+	cliConfig := cfg.CliConfiguration{Stacks: cfg.Stacks{MaxConcurrency: 3}}
+
+	_, _, _, err := ProcessYAMLConfigFiles(
+		cliConfig,
+		stacksBasePath,
+		filepath.Join(stacksBasePath, "components/terraform"),
+		filepath.Join(stacksBasePath, "components/helmfile"),
+		filePaths,
+		false,
+		false,
+		false,
+	)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "stack config file 'broken-1.yaml'")
+	assert.Contains(t, err.Error(), "stack config file 'broken-2.yaml'")
+	assert.NotContains(t, err.Error(), "good.yaml")
+
+	// The errors are reported in the order of the files
+	assert.Less(t, strings.Index(err.Error(), "broken-1.yaml"), strings.Index(err.Error(), "broken-2.yaml"))
+}
+
+func TestStackProcessorStopsAfterFirstError(t *testing.T) {
+	stacksBasePath := t.TempDir()
+
+	files := map[string]string{
+		"broken-1.yaml": `
+vars:
+  stage: [broken
+`,
+		"good.yaml": `
+vars:
+  stage: good
+`,
+		"broken-2.yaml": `
+import:
+  - catalog/missing
+`,
+	}
+
+	var filePaths []string
+	for _, name := range []string{"broken-1.yaml", "good.yaml", "broken-2.yaml"} {
+		p := filepath.Join(stacksBasePath, name)
+		err := os.WriteFile(p, []byte(files[name]), 0644)
+		assert.Nil(t, err)
+		filePaths = append(filePaths, p)
+	}
+
+	// The files are processed one by one, so the files after the first broken file are not processed
+	cliConfig := cfg.CliConfiguration{Stacks: cfg.Stacks{MaxConcurrency: 1}}
+
+	_, _, _, err := ProcessYAMLConfigFiles(
+		cliConfig,
+		stacksBasePath,
+		filepath.Join(stacksBasePath, "components/terraform"),
+		filepath.Join(stacksBasePath, "components/helmfile"),
+		filePaths,
+		false,
+		false,
+		false,
+	)
+
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "stack config file 'broken-1.yaml'")
+	assert.NotContains(t, err.Error(), "broken-2.yaml")
+}
+
+func TestStackProcessorDiamondImports(t *testing.T) {
+	stacksBasePath := "../../examples/complete/stacks"
+	terraformComponentsBasePath := "../../examples/complete/components/terraform"
+	helmfileComponentsBasePath := "../../examples/complete/components/helmfile"
+
+	// `dev.yaml` imports `private.yaml` and `public.yaml`, and both of them import `base.yaml`.
+	// `vpc-3` inherits from `vpc-1` and `vpc-2`, and both of them inherit from `vpc`
+	filePaths := []string{"../../examples/complete/stacks/tests/diamond-imports/dev.yaml"}
+
+	tests := []struct {
+		listMergeStrategy string
+		subnets           []any
+	}{
+		{
+			listMergeStrategy: cfg.ListMergeStrategyAppend,
+			subnets: []any{
+				map[any]any{"name": "private", "size": 24},
+				map[any]any{"size": 22},
+			},
+		},
+		{
+			listMergeStrategy: cfg.ListMergeStrategyMerge,
+			subnets: []any{
+				map[any]any{"name": "private", "size": 22},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.listMergeStrategy, func(t *testing.T) {
+			cliConfig := cfg.CliConfiguration{Settings: cfg.CliSettings{ListMergeStrategy: tt.listMergeStrategy}}
+
+			_, mapResult, _, err := ProcessYAMLConfigFiles(
+				cliConfig,
+				stacksBasePath,
+				terraformComponentsBasePath,
+				helmfileComponentsBasePath,
+				filePaths,
+				false,
+				false,
+				false,
+			)
+			assert.Nil(t, err)
+
+			terraformComponents := mapResult["tests/diamond-imports/dev"].(map[any]any)["components"].(map[string]any)["terraform"].(map[string]any)
+
+			// `base.yaml` is deep-merged only once
+			for _, component := range []string{"vpc", "vpc-1", "vpc-2", "vpc-3"} {
+				vars := terraformComponents[component].(map[string]any)["vars"].(map[any]any)
+				assert.Equal(t, []any{"us-east-2a", "us-east-2b"}, vars["availability_zones"], component)
+				assert.Equal(t, tt.subnets, vars["subnets"], component)
+			}
+
+			// `vpc` is deep-merged only once into `vpc-3`, after it `vpc-1` and `vpc-2`
+			vars := terraformComponents["vpc-3"].(map[string]any)["vars"].(map[any]any)
+			assert.Equal(t, "vpc-2", vars["name"])
+		})
+	}
+}
+
+func TestStackProcessorDiamondImportsProvenance(t *testing.T) {
+	stacksBasePath := "../../examples/complete/stacks"
+	filePath := "../../examples/complete/stacks/tests/diamond-imports/dev.yaml"
+
+	provenance := cfg.Provenance{}
+	_, _, _, err := ProcessYAMLConfigFileWithProvenance(cfg.CliConfiguration{}, stacksBasePath, filePath, map[string]map[any]any{}, nil, false, provenance)
+	assert.Nil(t, err)
+
+	// `base.yaml` is imported by both `private.yaml` and `public.yaml`, but its values are recorded only once
+	entries := provenance[ProvenanceKey([]string{"components", "terraform", "vpc", "vars", "availability_zones"})]
+	assert.Equal(t, 1, len(entries))
+	assert.Contains(t, entries[0].File, "tests/diamond-imports/catalog/base")
+
+	// The values from all the files are recorded in the order of deep-merging
+	entries = provenance[ProvenanceKey([]string{"components", "terraform", "vpc", "vars", "subnets"})]
+	assert.Equal(t, 2, len(entries))
+	assert.Contains(t, entries[0].File, "tests/diamond-imports/catalog/base")
+	assert.Contains(t, entries[1].File, "tests/diamond-imports/catalog/private")
+}
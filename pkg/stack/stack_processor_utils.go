@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -214,6 +215,7 @@ func sectionContainsAnyNotEmptySections(section map[any]any, sectionsToCheck []s
 
 // CreateComponentStackMap accepts a config file and creates a map of component-stack dependencies
 func CreateComponentStackMap(
+	cliConfig cfg.CliConfiguration,
 	stacksBasePath string,
 	terraformComponentsBasePath string,
 	helmfileComponentsBasePath string,
@@ -244,12 +246,13 @@ func CreateComponentStackMap(
 			isYaml := u.IsYaml(p)
 
 			if !isDirectory && isYaml {
-				config, _, _, err := ProcessYAMLConfigFile(stacksBasePath, p, map[string]map[any]any{}, nil, false)
+				config, _, _, err := ProcessYAMLConfigFile(cliConfig, stacksBasePath, p, map[string]map[any]any{}, nil, false)
 				if err != nil {
 					return err
 				}
 
 				finalConfig, err := ProcessStackConfig(
+					cliConfig,
 					stacksBasePath,
 					terraformComponentsBasePath,
 					helmfileComponentsBasePath,
@@ -309,6 +312,20 @@ func CreateComponentStackMap(
 	return componentStackMap, nil
 }
 
+// stacksMaxConcurrency returns the maximum number of stack config files processed concurrently.
+// If `stacks.max_concurrency` is not set in `atmos.yaml`, the number of CPUs is used
+func stacksMaxConcurrency(cliConfig cfg.CliConfiguration) int {
+	if cliConfig.Stacks.MaxConcurrency < 1 {
+		return runtime.NumCPU()
+	}
+	return cliConfig.Stacks.MaxConcurrency
+}
+
+// stackConfigFileError adds the path of the stack config file (relative to the stacks base path) to the error
+func stackConfigFileError(stacksBasePath string, filePath string, err error) error {
+	return fmt.Errorf("failed to process the stack config file '%s'\n%w", u.TrimBasePathFromPath(stacksBasePath+"/", filePath), err)
+}
+
 // getFileContent tries to read and return the file content from the sync map if it exists in the map,
 // otherwise it reads the file, stores its content in the map and returns the content
 func getFileContent(filePath string) (string, error) {
@@ -328,6 +345,7 @@ func getFileContent(filePath string) (string, error) {
 
 // ProcessBaseComponentConfig processes base component(s) config
 func ProcessBaseComponentConfig(
+	cliConfig cfg.CliConfiguration,
 	baseComponentConfig *cfg.BaseComponentConfig,
 	allComponentsMap map[any]any,
 	component string,
@@ -355,6 +373,11 @@ func ProcessBaseComponentConfig(
 
 	*baseComponents = append(*baseComponents, baseComponent)
 
+	// The base component has already been deep-merged (e.g. two base components inherit from it), don't merge its config again
+	if u.SliceContainsString(baseComponentConfig.ComponentInheritanceChain, baseComponent) {
+		return nil
+	}
+
 	if baseComponentSection, baseComponentSectionExist := allComponentsMap[baseComponent]; baseComponentSectionExist {
 		baseComponentMap, ok = baseComponentSection.(map[any]any)
 		if !ok {
@@ -377,6 +400,7 @@ func ProcessBaseComponentConfig(
 			}
 
 			err := ProcessBaseComponentConfig(
+				cliConfig,
 				baseComponentConfig,
 				allComponentsMap,
 				baseComponent,
@@ -422,6 +446,7 @@ func ProcessBaseComponentConfig(
 
 					// Process the baseComponentFromInheritList components recursively to find `componentInheritanceChain`
 					err := ProcessBaseComponentConfig(
+						cliConfig,
 						baseComponentConfig,
 						allComponentsMap,
 						component,
@@ -502,21 +527,21 @@ func ProcessBaseComponentConfig(
 		}
 
 		// Base component `vars`
-		merged, err := m.Merge([]map[any]any{baseComponentConfig.BaseComponentVars, baseComponentVars})
+		merged, err := m.Merge(cliConfig, []map[any]any{baseComponentConfig.BaseComponentVars, baseComponentVars})
 		if err != nil {
 			return err
 		}
 		baseComponentConfig.BaseComponentVars = merged
 
 		// Base component `settings`
-		merged, err = m.Merge([]map[any]any{baseComponentConfig.BaseComponentSettings, baseComponentSettings})
+		merged, err = m.Merge(cliConfig, []map[any]any{baseComponentConfig.BaseComponentSettings, baseComponentSettings})
 		if err != nil {
 			return err
 		}
 		baseComponentConfig.BaseComponentSettings = merged
 
 		// Base component `env`
-		merged, err = m.Merge([]map[any]any{baseComponentConfig.BaseComponentEnv, baseComponentEnv})
+		merged, err = m.Merge(cliConfig, []map[any]any{baseComponentConfig.BaseComponentEnv, baseComponentEnv})
 		if err != nil {
 			return err
 		}
@@ -529,7 +554,7 @@ func ProcessBaseComponentConfig(
 		baseComponentConfig.BaseComponentBackendType = baseComponentBackendType
 
 		// Base component `backend`
-		merged, err = m.Merge([]map[any]any{baseComponentConfig.BaseComponentBackendSection, baseComponentBackendSection})
+		merged, err = m.Merge(cliConfig, []map[any]any{baseComponentConfig.BaseComponentBackendSection, baseComponentBackendSection})
 		if err != nil {
 			return err
 		}
@@ -539,7 +564,7 @@ func ProcessBaseComponentConfig(
 		baseComponentConfig.BaseComponentRemoteStateBackendType = baseComponentRemoteStateBackendType
 
 		// Base component `remote_state_backend`
-		merged, err = m.Merge([]map[any]any{baseComponentConfig.BaseComponentRemoteStateBackendSection, baseComponentRemoteStateBackendSection})
+		merged, err = m.Merge(cliConfig, []map[any]any{baseComponentConfig.BaseComponentRemoteStateBackendSection, baseComponentRemoteStateBackendSection})
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,206 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	cfg "github.com/cloudposse/atmos/pkg/config"
+)
+
+const (
+	ProvenanceScopeGlobal        = "global"
+	ProvenanceScopeComponentType = "component-type"
+	ProvenanceScopeBaseComponent = "base-component"
+	ProvenanceScopeComponent     = "component"
+)
+
+// ProvenanceKey returns the key of the value in the provenance: the keys of the path joined with dots.
+// The dots and backslashes in the keys are escaped with a backslash (e.g. `vars.tags.kubernetes\.io/cluster`),
+// so that the keys containing dots don't collide with the nested keys
+func ProvenanceKey(keyPath []string) string {
+	escaped := make([]string, len(keyPath))
+	for i, key := range keyPath {
+		escaped[i] = provenanceKeyReplacer.Replace(key)
+	}
+	return strings.Join(escaped, ".")
+}
+
+var provenanceKeyReplacer = strings.NewReplacer(`\`, `\\`, ".", `\.`)
+
+// recordProvenance parses the content of the stack config file and adds the file and the line of each value to the provenance.
+// Only the leaf values (not maps) are recorded. Lists are recorded as leaf values since they are not deep-merged
+func recordProvenance(provenance cfg.Provenance, file string, content string) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
+		return fmt.Errorf("invalid stack config file '%s'\n%v", file, err)
+	}
+
+	return recordProvenanceNode(provenance, file, &node, nil)
+}
+
+func recordProvenanceNode(provenance cfg.Provenance, file string, node *yaml.Node, keyPath []string) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, n := range node.Content {
+			if err := recordProvenanceNode(provenance, file, n, keyPath); err != nil {
+				return err
+			}
+		}
+
+	case yaml.AliasNode:
+		return recordProvenanceNode(provenance, file, node.Alias, keyPath)
+
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			value := node.Content[i+1]
+
+			// YAML merge keys (`<<: *anchor`) add the keys of the anchor to the current map
+			if key.Value == "<<" {
+				if err := recordProvenanceNode(provenance, file, value, keyPath); err != nil {
+					return err
+				}
+				continue
+			}
+
+			itemPath := make([]string, len(keyPath), len(keyPath)+1)
+			copy(itemPath, keyPath)
+			itemPath = append(itemPath, key.Value)
+
+			if err := recordProvenanceNode(provenance, file, value, itemPath); err != nil {
+				return err
+			}
+		}
+
+	default:
+		if len(keyPath) == 0 {
+			return nil
+		}
+
+		var value any
+		if err := node.Decode(&value); err != nil {
+			return fmt.Errorf("invalid value of '%s' in the stack config file '%s' at line %d\n%v", ProvenanceKey(keyPath), file, node.Line, err)
+		}
+
+		key := ProvenanceKey(keyPath)
+		provenance[key] = append(provenance[key], cfg.ProvenanceEntry{
+			File:  file,
+			Line:  node.Line,
+			Value: value,
+		})
+	}
+
+	return nil
+}
+
+// FindComponentProvenance returns the provenance of all the leaf values in the `vars`, `settings`, `env` and `backend` sections
+// of the component (the final component section in the stack, as returned from `ProcessStackConfig`).
+// `stackProvenance` is the provenance of the stack config file recorded by `ProcessYAMLConfigFileWithProvenance`.
+// The values are looked up in the same order as `ProcessStackConfig` deep-merges them: the global sections, the component type sections,
+// the base components from the inheritance chain, and then the component itself
+func FindComponentProvenance(
+	stackProvenance cfg.Provenance,
+	componentType string,
+	component string,
+	componentSection map[string]any,
+) cfg.Provenance {
+
+	result := cfg.Provenance{}
+
+	var inheritanceChain []string
+	switch inheritance := componentSection["inheritance"].(type) {
+	case []string:
+		inheritanceChain = inheritance
+	case []any:
+		for _, v := range inheritance {
+			if s, ok := v.(string); ok {
+				inheritanceChain = append(inheritanceChain, s)
+			}
+		}
+	}
+
+	for _, section := range []string{"vars", "settings", "env"} {
+		scopes := []provenanceScope{
+			{scope: ProvenanceScopeGlobal, path: []string{section}},
+			{scope: ProvenanceScopeComponentType, path: []string{componentType, section}},
+		}
+		scopes = append(scopes, baseComponentProvenanceScopes(componentType, inheritanceChain, section)...)
+		scopes = append(scopes, provenanceScope{scope: ProvenanceScopeComponent, path: []string{"components", componentType, component, section}})
+
+		findSectionProvenance(stackProvenance, result, []string{section}, componentSection[section], scopes)
+	}
+
+	if componentType == "terraform" {
+		if backendType, ok := componentSection["backend_type"].(string); ok && backendType != "" {
+			scopes := []provenanceScope{
+				{scope: ProvenanceScopeComponentType, path: []string{componentType, "backend", backendType}},
+			}
+			scopes = append(scopes, baseComponentProvenanceScopes(componentType, inheritanceChain, "backend", backendType)...)
+			scopes = append(scopes, provenanceScope{scope: ProvenanceScopeComponent, path: []string{"components", componentType, component, "backend", backendType}})
+
+			findSectionProvenance(stackProvenance, result, []string{"backend"}, componentSection["backend"], scopes)
+		}
+	}
+
+	return result
+}
+
+// provenanceScope is a section of the stack config that is deep-merged into a section of the component
+type provenanceScope struct {
+	scope         string
+	baseComponent string
+	path          []string
+}
+
+// baseComponentProvenanceScopes returns the scopes of the base components.
+// The inheritance chain starts from the closest base component, so the base components are deep-merged in the reverse order
+func baseComponentProvenanceScopes(componentType string, inheritanceChain []string, sectionPath ...string) []provenanceScope {
+	var scopes []provenanceScope
+	for i := len(inheritanceChain) - 1; i >= 0; i-- {
+		baseComponent := inheritanceChain[i]
+		scopes = append(scopes, provenanceScope{
+			scope:         ProvenanceScopeBaseComponent,
+			baseComponent: baseComponent,
+			path:          append([]string{"components", componentType, baseComponent}, sectionPath...),
+		})
+	}
+	return scopes
+}
+
+// findSectionProvenance walks the final section of the component and adds the provenance of each leaf value to the result
+func findSectionProvenance(
+	stackProvenance cfg.Provenance,
+	result cfg.Provenance,
+	keyPath []string,
+	value any,
+	scopes []provenanceScope,
+) {
+	if m, ok := value.(map[any]any); ok {
+		for k, v := range m {
+			itemPath := make([]string, len(keyPath), len(keyPath)+1)
+			copy(itemPath, keyPath)
+			itemPath = append(itemPath, fmt.Sprintf("%v", k))
+			findSectionProvenance(stackProvenance, result, itemPath, v, scopes)
+		}
+		return
+	}
+
+	// The path of the value relative to the section
+	relativePath := keyPath[1:]
+	var entries []cfg.ProvenanceEntry
+
+	for _, scope := range scopes {
+		key := ProvenanceKey(append(append([]string{}, scope.path...), relativePath...))
+		for _, entry := range stackProvenance[key] {
+			entry.Scope = scope.scope
+			entry.BaseComponent = scope.baseComponent
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(entries) > 0 {
+		result[ProvenanceKey(keyPath)] = entries
+	}
+}
@@ -0,0 +1,78 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	e "github.com/cloudposse/atmos/internal/exec"
+	cfg "github.com/cloudposse/atmos/pkg/config"
+)
+
+func TestTerraformRunAllDependencyOrder(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	filter := e.TerraformRunAllFilter{
+		Stacks: []string{"tenant1-ue2-dev"},
+	}
+
+	results, err := e.ExecuteTerraformRunAll(cliConfig, "plan", filter, 3, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(results))
+
+	indexes := map[string]int{}
+	for i, result := range results {
+		assert.Equal(t, "tenant1-ue2-dev", result.Stack)
+		assert.Equal(t, "succeeded", result.Status)
+		indexes[result.Component] = i
+	}
+
+	// `top-level-component1` depends on `test/test-component-override` and `test/test-component` (from the `dev` stage)
+	assert.Greater(t, indexes["top-level-component1"], indexes["test/test-component-override"])
+	assert.Greater(t, indexes["top-level-component1"], indexes["test/test-component"])
+}
+
+func TestTerraformRunAllAffected(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	filter := e.TerraformRunAllFilter{
+		Affected: []cfg.Affected{
+			{Component: "top-level-component1", ComponentType: "terraform", Stack: "tenant1-ue2-prod"},
+			{Component: "infra/vpc", ComponentType: "terraform", Stack: "tenant1-ue2-prod"},
+			{Component: "test/test-component-override", ComponentType: "terraform", Stack: "tenant1-ue2-prod"},
+		},
+	}
+
+	results, err := e.ExecuteTerraformRunAll(cliConfig, "apply", filter, 1, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "top-level-component1", results[2].Component)
+
+	_, err = e.ExecuteTerraformRunAll(cliConfig, "destroy", filter, 1, true, nil)
+	assert.NotNil(t, err)
+}
+
+func TestTerraformRunAllTransitiveDependencies(t *testing.T) {
+	// `app` depends on `db`, and `db` depends on `network` (the names are sorted in the reverse order of the dependencies)
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/run-all-transitive/*")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	results, err := e.ExecuteTerraformRunAll(cliConfig, "plan", e.TerraformRunAllFilter{}, 3, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "network", results[0].Component)
+	assert.Equal(t, "db", results[1].Component)
+	assert.Equal(t, "app", results[2].Component)
+
+	// `app` is ordered after `network` even if `db` is not selected
+	filter := e.TerraformRunAllFilter{Components: []string{"app", "network"}}
+	results, err = e.ExecuteTerraformRunAll(cliConfig, "plan", filter, 3, true, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "network", results[0].Component)
+	assert.Equal(t, "app", results[1].Component)
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"os"
+)
+
+// LockFile acquires an exclusive lock on the lock file (the file is created if it does not exist).
+// It blocks until the lock is released by the other processes. The returned function releases the lock
+func LockFile(lockFilePath string) (func() error, error) {
+	f, err := os.OpenFile(lockFilePath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	unlock := func() error {
+		err := unlockFile(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	return unlock, nil
+}
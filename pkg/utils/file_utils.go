@@ -132,3 +132,18 @@ func SliceOfPathsContainsPath(paths []string, checkPath string) bool {
 	}
 	return false
 }
+
+// SliceOfPathsContainsPathInDir checks if a slice of file paths contains a path located in the dir or in any of its subdirs
+func SliceOfPathsContainsPathInDir(paths []string, dir string) bool {
+	for _, v := range paths {
+		if IsPathInDir(v, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPathInDir checks if the file path is located in the dir or in any of its subdirs
+func IsPathInDir(filePath string, dir string) bool {
+	return strings.HasPrefix(path.Clean(filePath), path.Clean(dir)+"/")
+}
@@ -1,14 +1,19 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/cloudposse/atmos/pkg/convert"
 	"github.com/hashicorp/hcl/hcl/ast"
 	"github.com/hashicorp/hcl/hcl/printer"
 	jsonParser "github.com/hashicorp/hcl/json/parser"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/zclconf/go-cty/cty"
 
 	"os"
+	"path"
+	"sort"
 	"strings"
 )
 
@@ -129,3 +134,126 @@ func WriteTerraformBackendConfigToFileAsHcl(filePath string, backendType string,
 
 	return nil
 }
+
+// terraformModuleSchema is the schema to read the `source` attribute of the `module` blocks in Terraform files
+var terraformModuleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+var terraformModuleSourceSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "source"},
+	},
+}
+
+// GetTerraformLocalModulePaths returns the paths to the local modules (the `source` of the `module` block starts with `./` or `../`)
+// used by the Terraform component in the provided folder, and the local modules used by those modules (recursively).
+// The returned paths are relative to the same dir as the provided component path. The component path itself is not included
+func GetTerraformLocalModulePaths(componentPath string) ([]string, error) {
+	visited := map[string]bool{path.Clean(componentPath): true}
+	var result []string
+
+	var visit func(dir string) error
+	visit = func(dir string) error {
+		sources, err := getTerraformLocalModuleSources(dir)
+		if err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			modulePath := path.Join(dir, source)
+			if visited[modulePath] {
+				continue
+			}
+			visited[modulePath] = true
+			result = append(result, modulePath)
+
+			if err = visit(modulePath); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := visit(componentPath); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// getTerraformLocalModuleSources parses the Terraform files in the dir and returns the `source` of all the local modules.
+// If the dir does not exist, an empty list is returned. The files that can't be parsed are skipped with a warning
+func getTerraformLocalModuleSources(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	var sources []string
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		filePath := path.Join(dir, entry.Name())
+
+		var file *hcl.File
+		var diags hcl.Diagnostics
+
+		switch {
+		case strings.HasSuffix(entry.Name(), ".tf"):
+			file, diags = parser.ParseHCLFile(filePath)
+		case strings.HasSuffix(entry.Name(), ".tf.json"):
+			file, diags = parser.ParseJSONFile(filePath)
+		default:
+			continue
+		}
+
+		if diags.HasErrors() {
+			PrintWarningToStdError(fmt.Sprintf("Skipping the Terraform file '%s' that can't be parsed: %s", filePath, diags.Error()))
+			continue
+		}
+
+		content, _, diags := file.Body.PartialContent(terraformModuleSchema)
+		if diags.HasErrors() {
+			PrintWarningToStdError(fmt.Sprintf("Skipping the Terraform file '%s' that can't be parsed: %s", filePath, diags.Error()))
+			continue
+		}
+
+		for _, block := range content.Blocks {
+			moduleContent, _, diags := block.Body.PartialContent(terraformModuleSourceSchema)
+			if diags.HasErrors() {
+				PrintWarningToStdError(fmt.Sprintf("Skipping the module '%s' in the Terraform file '%s' that can't be parsed: %s", block.Labels[0], filePath, diags.Error()))
+				continue
+			}
+
+			attribute, ok := moduleContent.Attributes["source"]
+			if !ok {
+				continue
+			}
+
+			// Only the literal values of `source` are supported (Terraform does not allow expressions in the module sources)
+			value, diags := attribute.Expr.Value(nil)
+			if diags.HasErrors() || value.IsNull() || value.Type() != cty.String {
+				continue
+			}
+
+			source := value.AsString()
+			if strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../") {
+				sources = append(sources, source)
+			}
+		}
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTerraformLocalModulePaths(t *testing.T) {
+	basePath := "../../examples/complete"
+
+	// The component uses the local module `label` (which uses the local module `policy`) and a module from the Terraform registry
+	modulePaths, err := GetTerraformLocalModulePaths(path.Join(basePath, "components/terraform/test/test-component-with-modules"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		path.Join(basePath, "components/modules/label"),
+		path.Join(basePath, "components/modules/policy"),
+	}, modulePaths)
+
+	// The component does not use local modules
+	modulePaths, err = GetTerraformLocalModulePaths(path.Join(basePath, "components/terraform/test/test-component"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(modulePaths))
+
+	// The component does not exist
+	modulePaths, err = GetTerraformLocalModulePaths(path.Join(basePath, "components/terraform/missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(modulePaths))
+}
+
+func TestGetTerraformLocalModulePathsWithInvalidFile(t *testing.T) {
+	componentPath := t.TempDir()
+
+	err := os.WriteFile(path.Join(componentPath, "main.tf"), []byte("module \"label\" {\n  source = \"../label\"\n}\n"), 0644)
+	assert.Nil(t, err)
+
+	// The files that can't be parsed are skipped
+	err = os.WriteFile(path.Join(componentPath, "invalid.tf"), []byte("module \"policy\" {\n  source = \n"), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(path.Join(componentPath, "invalid.tf.json"), []byte("{\"module\": "), 0644)
+	assert.Nil(t, err)
+
+	modulePaths, err := GetTerraformLocalModulePaths(componentPath)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{path.Join(path.Dir(componentPath), "label")}, modulePaths)
+}
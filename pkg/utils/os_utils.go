@@ -47,6 +47,16 @@ func PrintInfo(message string) {
 	color.Cyan("%s", message)
 }
 
+// PrintInfoToStdError prints the provided info message to std.Error
+func PrintInfoToStdError(message string) {
+	_, _ = color.New(color.FgCyan).Fprint(color.Error, message)
+}
+
+// PrintWarningToStdError prints the provided warning message to std.Error
+func PrintWarningToStdError(message string) {
+	_, _ = color.New(color.FgYellow).Fprintln(color.Error, message)
+}
+
 // PrintInfoVerbose checks the log level and prints the provided info message
 func PrintInfoVerbose(verbose bool, message string) {
 	if verbose {
@@ -14,6 +14,16 @@ func SliceContainsString(s []string, str string) bool {
 	return false
 }
 
+// SliceContainsAnyString checks if any of the provided strings is present in a slice
+func SliceContainsAnyString(s []string, strs []string) bool {
+	for _, str := range strs {
+		if SliceContainsString(s, str) {
+			return true
+		}
+	}
+	return false
+}
+
 // SliceContainsInt checks if an int is present in a slice
 func SliceContainsInt(s []int, i int) bool {
 	for _, v := range s {
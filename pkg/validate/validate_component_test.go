@@ -2,6 +2,7 @@ package validate
 
 import (
 	"github.com/stretchr/testify/assert"
+	"os"
 	"testing"
 
 	e "github.com/cloudposse/atmos/internal/exec"
@@ -41,3 +42,45 @@ func TestValidateComponent3(t *testing.T) {
 	u.PrintError(err)
 	assert.Error(t, err)
 }
+
+func TestValidateComponent4(t *testing.T) {
+	info := cfg.ConfigAndStacksInfo{}
+
+	cliConfig, err := cfg.InitCliConfig(info, true)
+	assert.Nil(t, err)
+
+	_, err = e.ExecuteValidateComponent(cliConfig, info, "infra/vpc", "tenant1-ue2-dev", "validate-infra-vpc-component.cue", "cue")
+	u.PrintError(err)
+	assert.Error(t, err)
+}
+
+func TestValidateWithCue(t *testing.T) {
+	schemaPath := "../../examples/complete/stacks/schemas/cue/validate-infra-vpc-component.cue"
+	schemaText, err := os.ReadFile(schemaPath)
+	assert.Nil(t, err)
+
+	data := map[string]any{
+		"vars": map[any]any{
+			"region":                  "us-east-2",
+			"cidr_block":              "10.10.0.0/18",
+			"map_public_ip_on_launch": false,
+			"name":                    "common",
+		},
+	}
+
+	valid, err := e.ValidateWithCue(data, schemaPath, string(schemaText))
+	assert.Nil(t, err)
+	assert.True(t, valid)
+
+	// The value does not match the schema
+	data["vars"].(map[any]any)["name"] = "co!!,mmon"
+	valid, err = e.ValidateWithCue(data, schemaPath, string(schemaText))
+	assert.False(t, valid)
+	assert.ErrorContains(t, err, "\"instanceLocation\": \"/vars/name\"")
+
+	// The schema can't be compiled
+	valid, err = e.ValidateWithCue(data, "invalid.cue", "vars: {\n\tname: string &\n")
+	assert.False(t, valid)
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "instanceLocation")
+}
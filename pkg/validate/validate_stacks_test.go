@@ -1,10 +1,11 @@
 package validate
 
 import (
+	"testing"
+
 	e "github.com/cloudposse/atmos/internal/exec"
 	u "github.com/cloudposse/atmos/pkg/utils"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 func TestValidateStacksCommand(t *testing.T) {
@@ -12,3 +13,22 @@ func TestValidateStacksCommand(t *testing.T) {
 	u.PrintError(err)
 	assert.NotNil(t, err)
 }
+
+func TestValidateStacksDependencies(t *testing.T) {
+	// `a` and `b` depend on each other, `c` depends on a component that does not exist,
+	// and `d` depends on the component `a` from the `prod` stage which is not defined.
+	// The catalog files are validated, but they are not stacks, so the dependencies of their components are not checked.
+	// The other invalid files in the `stacks` folder are reported, but they are not top-level stacks and don't prevent checking the dependencies
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/validate-dependencies/*")
+
+	err := e.ExecuteValidateStacksCmd(nil, nil)
+	assert.NotNil(t, err)
+	u.PrintError(err)
+
+	assert.Contains(t, err.Error(), "the terraform component 'c' in the stack 'tests-ue2-dev' (stack config file 'tests/validate-dependencies/dev.yaml') depends on the component 'missing' (namespace: cp, tenant: tests, environment: ue2, stage: dev)")
+	assert.Contains(t, err.Error(), "the terraform component 'd' in the stack 'tests-ue2-dev' (stack config file 'tests/validate-dependencies/dev.yaml') depends on the component 'a' (namespace: cp, tenant: tests, environment: ue2, stage: prod)")
+	assert.Contains(t, err.Error(), "dependency cycle")
+	assert.Contains(t, err.Error(), "the terraform component 'a' in the stack 'tests-ue2-dev'")
+	assert.Contains(t, err.Error(), "the terraform component 'b' in the stack 'tests-ue2-dev'")
+	assert.NotContains(t, err.Error(), "component 'e'")
+}
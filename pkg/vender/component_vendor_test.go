@@ -1,13 +1,18 @@
 package vender
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	e "github.com/cloudposse/atmos/internal/exec"
 	cfg "github.com/cloudposse/atmos/pkg/config"
+	cp "github.com/otiai10/copy"
 )
 
 func TestVendorComponentPullCommand(t *testing.T) {
@@ -22,7 +27,7 @@ func TestVendorComponentPullCommand(t *testing.T) {
 	componentConfig, componentPath, err := e.ReadAndProcessComponentConfigFile(cliConfig, component, componentType)
 	assert.Nil(t, err)
 
-	err = e.ExecuteComponentVendorCommandInternal(componentConfig.Spec, component, componentPath, false, vendorCommand)
+	err = e.ExecuteComponentVendorCommandInternal(cliConfig, componentConfig.Spec, component, componentPath, false, vendorCommand)
 	assert.Nil(t, err)
 
 	// Check if the correct files were pulled and written to the correct folder
@@ -38,7 +43,7 @@ func TestVendorComponentPullCommand(t *testing.T) {
 	componentConfig, componentPath, err = e.ReadAndProcessComponentConfigFile(cliConfig, component, componentType)
 	assert.Nil(t, err)
 
-	err = e.ExecuteComponentVendorCommandInternal(componentConfig.Spec, component, componentPath, false, vendorCommand)
+	err = e.ExecuteComponentVendorCommandInternal(cliConfig, componentConfig.Spec, component, componentPath, false, vendorCommand)
 	assert.Nil(t, err)
 
 	// Check if the correct files were pulled and written to the correct folder
@@ -79,3 +84,223 @@ func TestVendorComponentPullCommand(t *testing.T) {
 	err = os.RemoveAll(path.Join(componentPath, "modules"))
 	assert.Nil(t, err)
 }
+
+func TestVendorStackPullCommand(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	stacksMap, _, err := e.FindStacksMap(cliConfig, false)
+	assert.Nil(t, err)
+
+	componentFolders, err := e.FindComponentFoldersInStack(cliConfig, stacksMap, "tenant1-ue2-dev")
+	assert.Nil(t, err)
+	assert.Contains(t, componentFolders["terraform"], "infra/vpc")
+	assert.Contains(t, componentFolders["terraform"], "test/test-component")
+	assert.Contains(t, componentFolders["helmfile"], "echo-server")
+
+	_, err = e.FindComponentFoldersInStack(cliConfig, stacksMap, "tenant1-ue2-unknown")
+	assert.NotNil(t, err)
+
+	// Dry run does not download the component sources
+	err = e.ExecuteStackVendorCommandInternal(cliConfig, "tenant1-ue2-dev", true, "pull")
+	assert.Nil(t, err)
+
+	// The component folders without the vendor config file are skipped, but an invalid vendor config file is an error
+	cliConfig.BasePath = t.TempDir()
+	componentPath := path.Join(cliConfig.BasePath, cliConfig.Components.Terraform.BasePath, "infra/vpc")
+	err = os.MkdirAll(componentPath, 0755)
+	assert.Nil(t, err)
+	err = os.WriteFile(path.Join(componentPath, cfg.ComponentConfigFileName), []byte("kind: Unknown\n"), 0644)
+	assert.Nil(t, err)
+
+	err = e.ExecuteStackVendorCommandInternal(cliConfig, "tenant1-ue2-dev", true, "pull")
+	assert.ErrorContains(t, err, "invalid 'kind: Unknown'")
+}
+
+func TestVendorComponentDiffCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	componentPath := t.TempDir()
+
+	cliConfig := cfg.CliConfiguration{}
+	cliConfig.Vendor.MaxConcurrency = 2
+
+	// Local archives are extracted by go-getter into the temp folder
+	sourceArchive := path.Join(tempDir, "source.tar.gz")
+	err := writeTarGz(sourceArchive, map[string]string{
+		"main.tf":      "resource \"null_resource\" \"this\" {}\n",
+		"variables.tf": "variable \"enabled\" {}\n",
+		"README.md":    "# Test component\n",
+	})
+	assert.Nil(t, err)
+
+	vendorComponentSpec := cfg.VendorComponentSpec{
+		Source: cfg.VendorComponentSource{
+			Uri:           sourceArchive,
+			IncludedPaths: []string{"**/*.tf"},
+		},
+	}
+
+	// Pull the component and check that there is no drift
+	err = e.ExecuteComponentVendorCommandInternal(cliConfig, vendorComponentSpec, "test", componentPath, false, "pull")
+	assert.Nil(t, err)
+	assert.FileExists(t, path.Join(componentPath, "main.tf"))
+	assert.NoFileExists(t, path.Join(componentPath, "README.md"))
+
+	err = e.ExecuteComponentVendorCommandInternal(cliConfig, vendorComponentSpec, "test", componentPath, false, "diff")
+	assert.Nil(t, err)
+
+	// Edit the vendored file and check that the drift is detected
+	err = os.WriteFile(path.Join(componentPath, "main.tf"), []byte("resource \"null_resource\" \"that\" {}\n"), 0644)
+	assert.Nil(t, err)
+
+	// Only the diff is written to stdout, the progress messages are written to stderr
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+	os.Stdout = w
+
+	err = e.ExecuteComponentVendorCommandInternal(cliConfig, vendorComponentSpec, "test", componentPath, false, "diff")
+	assert.IsType(t, &e.VendorDriftError{}, err)
+
+	os.Stdout = stdout
+	assert.Nil(t, w.Close())
+	output, err := io.ReadAll(r)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(strings.TrimSpace(string(output)), "--- "))
+	assert.Contains(t, string(output), "+resource \"null_resource\" \"that\" {}")
+	assert.NotContains(t, string(output), "Including")
+}
+
+func TestVendorStackDiffCommand(t *testing.T) {
+	// Both components are vendored from the same upstream folder, and both have the vendored file edited
+	t.Setenv("ATMOS_STACKS_INCLUDED_PATHS", "tests/vendor-diff/*")
+	t.Setenv("ATMOS_VENDOR_MAX_CONCURRENCY", "1")
+
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// The drift of all the components is reported, not only of the first one
+	err = e.ExecuteStackVendorCommandInternal(cliConfig, "tests-ue2-dev", false, "diff")
+	driftErr, ok := err.(*e.VendorDriftError)
+	assert.True(t, ok)
+	if ok {
+		assert.Equal(t, 2, len(driftErr.Drifts))
+	}
+}
+
+func writeTarGz(archivePath string, files map[string]string) error {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	for name, content := range files {
+		if err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			return err
+		}
+		if _, err = tw.Write([]byte(content)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestVendorAtmosVendorConfigPullCommand(t *testing.T) {
+	// The sources are vendored from the local `upstream` folder, which is copied with the vendor config into a temp dir
+	basePath := t.TempDir()
+	err := cp.Copy("../../examples/complete/tests/vendor/tags", basePath)
+	assert.Nil(t, err)
+
+	cliConfig := cfg.CliConfiguration{}
+	cliConfig.Vendor.MaxConcurrency = 2
+	cliConfig.Vendor.Cache.Enabled = true
+	cliConfig.Vendor.Cache.BasePath = t.TempDir()
+
+	vendorConfigFile := path.Join(basePath, cfg.AtmosVendorConfigFileName)
+
+	// Pull only the sources tagged with `networking`
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, []string{"networking"}, false, "pull")
+	assert.Nil(t, err)
+	assert.FileExists(t, path.Join(basePath, "components/terraform/vpc/main.tf"))
+	assert.NoFileExists(t, path.Join(basePath, "components/terraform/vpc/README.md"))
+	assert.NoDirExists(t, path.Join(basePath, "components/terraform/eks"))
+
+	vendorLock, err := e.ReadAtmosVendorLockFile(path.Join(basePath, cfg.AtmosVendorLockFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(vendorLock.Spec.Sources))
+	assert.Equal(t, "upstream/vpc", vendorLock.Spec.Sources[0].Uri)
+	assert.Equal(t, []string{"components/terraform/vpc"}, vendorLock.Spec.Sources[0].Targets)
+	assert.Contains(t, vendorLock.Spec.Sources[0].Checksum, "sha256:")
+
+	// Pull all the sources
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, nil, false, "pull")
+	assert.Nil(t, err)
+	assert.FileExists(t, path.Join(basePath, "components/terraform/eks/variables.tf"))
+	assert.FileExists(t, path.Join(basePath, "components/terraform/eks-copy/variables.tf"))
+
+	vendorLock2, err := e.ReadAtmosVendorLockFile(path.Join(basePath, cfg.AtmosVendorLockFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(vendorLock2.Spec.Sources))
+	assert.Equal(t, vendorLock.Spec.Sources[0].Checksum, vendorLock2.Spec.Sources[0].Checksum)
+
+	// Check that the vendored files are the same as the upstream files
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, nil, false, "diff")
+	assert.Nil(t, err)
+
+	// Edit the vendored file and check that the next pull does not skip the source and restores the file
+	vpcMainFile := path.Join(basePath, "components/terraform/vpc/main.tf")
+	err = os.WriteFile(vpcMainFile, []byte("resource \"null_resource\" \"edited\" {}\n"), 0644)
+	assert.Nil(t, err)
+
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, []string{"networking"}, false, "pull")
+	assert.Nil(t, err)
+
+	vpcMainFileContent, err := os.ReadFile(vpcMainFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "resource \"null_resource\" \"vpc\" {}\n", string(vpcMainFileContent))
+
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, []string{"unknown"}, false, "pull")
+	assert.NotNil(t, err)
+}
+
+func TestVendorAtmosVendorConfigPullCommandWithComponentVersions(t *testing.T) {
+	// The same component is vendored twice with different versions into different targets
+	basePath := t.TempDir()
+	err := cp.Copy("../../examples/complete/tests/vendor/versions", basePath)
+	assert.Nil(t, err)
+
+	cliConfig := cfg.CliConfiguration{}
+
+	vendorConfigFile := path.Join(basePath, cfg.AtmosVendorConfigFileName)
+
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, nil, false, "pull")
+	assert.Nil(t, err)
+	assert.FileExists(t, path.Join(basePath, "components/terraform/vpc/1.0.0/main.tf"))
+	assert.FileExists(t, path.Join(basePath, "components/terraform/vpc/2.0.0/main.tf"))
+
+	// Both versions are kept in the lock file
+	vendorLock, err := e.ReadAtmosVendorLockFile(path.Join(basePath, cfg.AtmosVendorLockFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(vendorLock.Spec.Sources))
+	assert.Equal(t, []string{"components/terraform/vpc/1.0.0"}, vendorLock.Spec.Sources[0].Targets)
+	assert.Equal(t, []string{"components/terraform/vpc/2.0.0"}, vendorLock.Spec.Sources[1].Targets)
+	assert.NotEqual(t, vendorLock.Spec.Sources[0].Checksum, vendorLock.Spec.Sources[1].Checksum)
+
+	// Edit one of the versions and check that the drift is reported only for it
+	err = os.WriteFile(path.Join(basePath, "components/terraform/vpc/2.0.0/main.tf"), []byte("# edited\n"), 0644)
+	assert.Nil(t, err)
+
+	err = e.ExecuteAtmosVendorInternal(cliConfig, vendorConfigFile, nil, false, "diff")
+	assert.IsType(t, &e.VendorDriftError{}, err)
+	if driftErr, ok := err.(*e.VendorDriftError); ok {
+		assert.Equal(t, []string{"the component 'vpc' (targets: components/terraform/vpc/2.0.0)"}, driftErr.Drifts)
+	}
+}
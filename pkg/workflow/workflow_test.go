@@ -1,17 +1,26 @@
 package workflow
 
 import (
-	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	e "github.com/cloudposse/atmos/internal/exec"
 	cfg "github.com/cloudposse/atmos/pkg/config"
 )
 
 func TestWorkflowCommand(t *testing.T) {
-	_, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
 	assert.Nil(t, err)
 
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
 	workflow := "test-1"
 	workflowPath := "stacks/workflows/workflow1.yaml"
 
@@ -42,6 +51,7 @@ func TestWorkflowCommand(t *testing.T) {
 	}
 
 	err = e.ExecuteWorkflow(
+		cliConfig,
 		workflow,
 		workflowPath,
 		&workflowDefinition,
@@ -50,10 +60,13 @@ func TestWorkflowCommand(t *testing.T) {
 		// `step3` name is not defined in the workflow, so we auto-generate a friendly name consisting of
 		// a prefix of `step` and followed by the index of the step (the index starts with 1, so the first generated step name would be `step1`)
 		"step3",
+		nil,
+		false,
 	)
 	assert.Nil(t, err)
 
 	err = e.ExecuteWorkflow(
+		cliConfig,
 		workflow,
 		workflowPath,
 		&workflowDefinition,
@@ -61,6 +74,578 @@ func TestWorkflowCommand(t *testing.T) {
 		"",
 		// The workflow does not have 5 steps, we we should get an error
 		"step5",
+		nil,
+		false,
 	)
 	assert.Error(t, err)
 }
+
+func TestWorkflowCommandWithNeeds(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-2"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+	outputFile := path.Join(t.TempDir(), "output.txt")
+
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 2",
+		MaxParallel: 2,
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "plan-ue2",
+				Type:    "shell",
+				Command: "echo plan-ue2 >> " + outputFile,
+			},
+			{
+				Name:    "plan-uw2",
+				Type:    "shell",
+				Command: "echo plan-uw2 >> " + outputFile,
+			},
+			{
+				Name:    "apply",
+				Type:    "shell",
+				Command: "echo apply >> " + outputFile,
+				Needs:   []string{"plan-ue2", "plan-uw2"},
+			},
+		},
+	}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Nil(t, err)
+
+	output, err := os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	assert.Equal(t, 3, len(lines))
+	// The `apply` step must be executed after both `plan` steps
+	assert.Equal(t, "apply", lines[2])
+
+	// `--from-step` skips the previous steps, and the skipped steps satisfy the `needs` of the other steps
+	err = os.Remove(outputFile)
+	assert.Nil(t, err)
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "apply", nil, false)
+	assert.Nil(t, err)
+
+	output, err = os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "apply", strings.TrimSpace(string(output)))
+
+	// The steps that need a failed step are not executed
+	err = os.Remove(outputFile)
+	assert.Nil(t, err)
+
+	workflowDefinition.Steps[0].Command = "exit 1"
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Error(t, err)
+
+	output, err = os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(output), "apply")
+
+	// The `needs` attribute must refer to an existing step
+	workflowDefinition.Steps[2].Needs = []string{"plan-ue1"}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Error(t, err)
+
+	// The dependencies between the steps must not have cycles
+	workflowDefinition.Steps[0].Needs = []string{"apply"}
+	workflowDefinition.Steps[2].Needs = []string{"plan-ue2"}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Error(t, err)
+}
+
+func TestWorkflowCommandWithNeedsPrefixesOutput(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-2"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+
+	// The steps declare `needs` and run one by one (`max_parallel` is not set)
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 2",
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "plan",
+				Type:    "shell",
+				Command: "echo plan-output",
+			},
+			{
+				Name:    "apply",
+				Type:    "shell",
+				Command: "echo apply-output",
+				Needs:   []string{"plan"},
+			},
+		},
+	}
+
+	output := captureWorkflowStdout(t, func() {
+		err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, output, "[plan] plan-output\n")
+	assert.Contains(t, output, "[apply] apply-output\n")
+
+	// Without `needs` and `max_parallel`, the steps write directly to the terminal
+	workflowDefinition.Steps[1].Needs = nil
+
+	output = captureWorkflowStdout(t, func() {
+		err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, output, "plan-output\n")
+	assert.NotContains(t, output, "[plan]")
+}
+
+// captureWorkflowStdout returns what the function writes to the stdout
+func captureWorkflowStdout(t *testing.T, f func()) string {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	assert.Nil(t, err)
+
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	output := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		output <- string(b)
+	}()
+
+	f()
+
+	assert.Nil(t, w.Close())
+	return <-output
+}
+
+func TestWorkflowCommandWithRetries(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-3"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+	attemptsFile := path.Join(t.TempDir(), "attempts.txt")
+	outputFile := path.Join(t.TempDir(), "output.txt")
+	continueOnError := true
+
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 3",
+		Retry: &cfg.WorkflowStepRetry{
+			MaxAttempts: 3,
+			Delay:       "10ms",
+			Backoff:     "exponential",
+		},
+		Steps: []cfg.WorkflowStep{
+			{
+				// The step fails two times and succeeds on the third attempt
+				Name:    "flaky",
+				Type:    "shell",
+				Command: "echo attempt >> " + attemptsFile + " && test $(wc -l < " + attemptsFile + ") -ge 3",
+			},
+			{
+				Name:            "timeout",
+				Type:            "shell",
+				Command:         "sleep 5",
+				Timeout:         "100ms",
+				Retry:           &cfg.WorkflowStepRetry{MaxAttempts: 1},
+				ContinueOnError: &continueOnError,
+			},
+			{
+				Name:    "last",
+				Type:    "shell",
+				Command: "echo last >> " + outputFile,
+				Needs:   []string{"timeout"},
+			},
+		},
+	}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Nil(t, err)
+
+	attempts, err := os.ReadFile(attemptsFile)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(strings.Split(strings.TrimSpace(string(attempts)), "\n")))
+
+	// The step after the step with `continue_on_error` is executed
+	output, err := os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "last", strings.TrimSpace(string(output)))
+
+	// Without `continue_on_error`, the timed out step fails the workflow
+	continueOnError = false
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "timeout", nil, false)
+	assert.ErrorContains(t, err, "timed out")
+
+	// Invalid durations are reported before executing the workflow
+	workflowDefinition.Timeout = "ten minutes"
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Error(t, err)
+}
+
+func TestWorkflowCommandWithInputsAndOutputs(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-4"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+	outputFile := path.Join(t.TempDir(), "output.txt")
+
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 4",
+		Inputs: map[string]cfg.WorkflowInput{
+			"stage": {
+				Type:     "string",
+				Required: true,
+			},
+			"replicas": {
+				Type:    "number",
+				Default: 2,
+			},
+		},
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "get-cluster-name",
+				Type:    "shell",
+				Command: "echo Getting the cluster name; echo cluster_name=eks-{{ .inputs.stage }}; echo region=us-east-2",
+				Outputs: []string{"cluster_name", "region"},
+			},
+			{
+				Name:    "update-kubeconfig",
+				Type:    "shell",
+				Command: "echo '{{ (index .steps \"get-cluster-name\").outputs.cluster_name }} {{ (index .steps \"get-cluster-name\").outputs.region }} {{ .inputs.replicas }}' > " + outputFile,
+				Needs:   []string{"get-cluster-name"},
+			},
+		},
+	}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "dev"}, false)
+	assert.Nil(t, err)
+
+	output, err := os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "eks-dev us-east-2 2", strings.TrimSpace(string(output)))
+
+	// The required input is not provided
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Error(t, err)
+
+	// The input is not declared in the workflow
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "dev", "region": "us-east-2"}, false)
+	assert.Error(t, err)
+
+	// The input value does not match the input type
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "dev", "replicas": "two"}, false)
+	assert.Error(t, err)
+
+	// The step does not write the declared output
+	workflowDefinition.Steps[0].Outputs = []string{"cluster_name", "vpc_id"}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "dev"}, false)
+	assert.Error(t, err)
+}
+
+func TestWorkflowCommandWithLiteralTemplateDelimiters(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-5"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+	outputFile := path.Join(t.TempDir(), "output.txt")
+
+	// The workflow does not declare inputs or outputs, so the commands are executed as is
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 5",
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "docker-ps",
+				Type:    "shell",
+				Command: "echo \"docker ps --format '{{.Names}}'\" > " + outputFile,
+			},
+		},
+	}
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Nil(t, err)
+
+	output, err := os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "docker ps --format '{{.Names}}'", strings.TrimSpace(string(output)))
+}
+
+func TestWorkflowCommandWithLiteralVariables(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	t.Setenv("bar", "expanded")
+
+	workflow := "test-6"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+
+	// The `$bar` variable in the `atmos` command is passed to `atmos` literally
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 6",
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "plan",
+				Command: "terraform plan vpc -var foo=$bar -var 'tags={a,b}'",
+				Stack:   "tenant1-ue2-dev",
+			},
+		},
+	}
+
+	output := captureWorkflowStdout(t, func() {
+		err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, true, "", "", nil, false)
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, output, "Executing command: atmos terraform plan vpc -var foo=$bar -var 'tags={a,b}' -s tenant1-ue2-dev\n")
+
+	// In the workflows that use templates, the quotes are removed, but the variables are still passed literally
+	workflowDefinition.Inputs = map[string]cfg.WorkflowInput{
+		"name": {
+			Type:    "string",
+			Default: "my vpc",
+		},
+	}
+	workflowDefinition.Steps[0].Command = "terraform plan vpc -var foo=$bar -var 'name={{ .inputs.name }}' -var \"tags={a,b}\""
+
+	output = captureWorkflowStdout(t, func() {
+		err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, true, "", "", nil, false)
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, output, "Executing command: atmos terraform plan vpc -var foo=$bar -var name=my vpc -var tags={a,b} -s tenant1-ue2-dev\n")
+}
+
+func TestWorkflowCommandResume(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-5"
+	workflowPath := path.Join(cliConfig.BasePath, cliConfig.Workflows.BasePath, "workflow1.yaml")
+	outputFile := path.Join(t.TempDir(), "output.txt")
+	failFile := path.Join(t.TempDir(), "fail")
+
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 5",
+		Inputs: map[string]cfg.WorkflowInput{
+			"stage": {},
+		},
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "step1",
+				Type:    "shell",
+				Command: "echo step1 >> " + outputFile + " && echo value={{ .inputs.stage }}",
+				Outputs: []string{"value"},
+			},
+			{
+				// The step fails while the `fail` file exists
+				Name:    "step2",
+				Type:    "shell",
+				Command: "echo step2-{{ .steps.step1.outputs.value }} >> " + outputFile + " && test ! -f " + failFile,
+			},
+		},
+	}
+
+	err = os.WriteFile(failFile, []byte{}, 0644)
+	assert.Nil(t, err)
+
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "dev"}, false)
+	assert.Error(t, err)
+
+	state, err := e.ReadWorkflowStateFile(path.Join(cliConfig.Workflows.State.BasePath, cfg.WorkflowStateFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(state.Runs))
+	assert.Equal(t, "workflow1.yaml", state.Runs[0].File)
+	assert.Equal(t, "failed", state.Runs[0].Status)
+	assert.Equal(t, "succeeded", state.Runs[0].Steps[0].Status)
+	assert.Equal(t, "failed", state.Runs[0].Steps[1].Status)
+
+	err = os.Remove(failFile)
+	assert.Nil(t, err)
+
+	// `step1` succeeded in the last run and is not executed again. Its outputs are restored from the state file
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "dev"}, true)
+	assert.Nil(t, err)
+
+	output, err := os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "step1\nstep2-dev\nstep2-dev", strings.TrimSpace(string(output)))
+
+	// The run with different inputs does not resume the previous runs
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", map[string]string{"stage": "prod"}, true)
+	assert.Nil(t, err)
+
+	output, err = os.ReadFile(outputFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "step1\nstep2-dev\nstep2-dev\nstep1\nstep2-prod", strings.TrimSpace(string(output)))
+
+	state, err = e.ReadWorkflowStateFile(path.Join(cliConfig.Workflows.State.BasePath, cfg.WorkflowStateFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(state.Runs))
+	assert.Equal(t, "resumed", state.Runs[1].Steps[0].Status)
+	assert.Equal(t, "succeeded", state.Runs[1].Status)
+
+	// `--resume` and `--from-step` can't be used together
+	err = e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "step2", map[string]string{"stage": "dev"}, true)
+	assert.Error(t, err)
+}
+
+func TestWorkflowCommandConcurrentRuns(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	// Record the workflow runs in a temp folder
+	cliConfig.Workflows.State.BasePath = t.TempDir()
+
+	workflow := "test-6"
+	workflowPath := "stacks/workflows/workflow1.yaml"
+
+	workflowDefinition := cfg.WorkflowDefinition{
+		Description: "Test workflow 6",
+		Steps: []cfg.WorkflowStep{
+			{
+				Name:    "step1",
+				Type:    "shell",
+				Command: "echo 1",
+			},
+		},
+	}
+
+	// The runs recorded concurrently by multiple processes must all be kept in the state file
+	runs := 10
+	var wg sync.WaitGroup
+
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := e.ExecuteWorkflow(cliConfig, workflow, workflowPath, &workflowDefinition, false, "", "", nil, false)
+			assert.Nil(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	state, err := e.ReadWorkflowStateFile(path.Join(cliConfig.Workflows.State.BasePath, cfg.WorkflowStateFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, runs, len(state.Runs))
+}
+
+func TestFindWorkflow(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	workflowFiles, err := e.FindWorkflowFiles(cliConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"workflow1.yaml"}, workflowFiles)
+
+	// The workflow name is unique, so the file is not required
+	workflowPath, workflowDefinition, err := e.FindWorkflow(cliConfig, "test-parallel", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "workflow1.yaml", path.Base(workflowPath))
+	assert.Equal(t, 2, workflowDefinition.MaxParallel)
+
+	// The file can be specified without the extension
+	workflowPath2, _, err := e.FindWorkflow(cliConfig, "test-parallel", "workflow1")
+	assert.Nil(t, err)
+	assert.Equal(t, workflowPath, workflowPath2)
+
+	_, _, err = e.FindWorkflow(cliConfig, "not-existing-workflow", "")
+	assert.NotNil(t, err)
+
+	_, _, err = e.FindWorkflow(cliConfig, "not-existing-workflow", "workflow1")
+	assert.NotNil(t, err)
+}
+
+func TestFindWorkflowAmbiguous(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	cliConfig.BasePath = t.TempDir()
+	cliConfig.Workflows.BasePath = "workflows"
+
+	workflowsDir := path.Join(cliConfig.BasePath, cliConfig.Workflows.BasePath)
+	err = os.MkdirAll(path.Join(workflowsDir, "nested"), 0755)
+	assert.Nil(t, err)
+
+	workflowFile := "workflows:\n  deploy:\n    steps:\n      - command: echo deploy\n        type: shell\n"
+
+	err = os.WriteFile(path.Join(workflowsDir, "a.yaml"), []byte(workflowFile), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile(path.Join(workflowsDir, "nested", "b.yaml"), []byte(workflowFile), 0644)
+	assert.Nil(t, err)
+	// Not a workflow file, must be skipped
+	err = os.WriteFile(path.Join(workflowsDir, "other.yaml"), []byte("vars: {}\n"), 0644)
+	assert.Nil(t, err)
+
+	workflowFiles, err := e.FindWorkflowFiles(cliConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.yaml", "nested/b.yaml", "other.yaml"}, workflowFiles)
+
+	_, _, err = e.FindWorkflow(cliConfig, "deploy", "")
+	assert.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "more than one file"))
+
+	workflowPath, _, err := e.FindWorkflow(cliConfig, "deploy", "nested/b")
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(workflowsDir, "nested", "b.yaml"), workflowPath)
+}
+
+func TestWorkflowStateFileDefaultPath(t *testing.T) {
+	cliConfig, err := cfg.InitCliConfig(cfg.ConfigAndStacksInfo{}, true)
+	assert.Nil(t, err)
+
+	cliConfig.BasePath = t.TempDir()
+	cliConfig.Workflows.BasePath = "workflows"
+	cliConfig.Workflows.State.BasePath = ""
+
+	workflowsDir := path.Join(cliConfig.BasePath, cliConfig.Workflows.BasePath)
+	err = os.MkdirAll(workflowsDir, 0755)
+	assert.Nil(t, err)
+
+	err = os.WriteFile(path.Join(workflowsDir, "a.yaml"), []byte("workflows:\n  deploy:\n    steps:\n      - command: echo deploy\n        type: shell\n"), 0644)
+	assert.Nil(t, err)
+
+	workflowPath, workflowDefinition, err := e.FindWorkflow(cliConfig, "deploy", "")
+	assert.Nil(t, err)
+
+	err = e.ExecuteWorkflow(cliConfig, "deploy", workflowPath, &workflowDefinition, false, "", "", nil, false)
+	assert.Nil(t, err)
+
+	// If `workflows.state.base_path` is not configured, the runs are recorded in the workflows base path
+	state, err := e.ReadWorkflowStateFile(path.Join(workflowsDir, cfg.WorkflowStateFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(state.Runs))
+	assert.Equal(t, "a.yaml", state.Runs[0].File)
+
+	// The state file is not a workflow file
+	workflowFiles, err := e.FindWorkflowFiles(cliConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.yaml"}, workflowFiles)
+}